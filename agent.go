@@ -0,0 +1,333 @@
+package pve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrAgentDisabled is returned when the guest agent is not enabled in the VM configuration
+	ErrAgentDisabled = errors.New("qemu guest agent is not enabled in VM configuration")
+	// ErrAgentNotResponding is returned when the guest agent is enabled but does not answer
+	ErrAgentNotResponding = errors.New("qemu guest agent is not responding")
+	// ErrFreezeFailed is returned when a snapshot was taken without frozen guest filesystems
+	ErrFreezeFailed = errors.New("guest filesystems were not frozen")
+)
+
+// AgentAvailable reports whether the QEMU guest agent can be used.
+// It returns ErrAgentDisabled if the agent is disabled in the VM configuration
+// and ErrAgentNotResponding (wrapping the ping error) if the agent does not answer.
+func (s *QEMUService) AgentAvailable(ctx context.Context, node string, vmid int) (bool, error) {
+	config, err := s.getConfig(ctx, node, vmid)
+	if err != nil {
+		return false, err
+	}
+
+	if config == nil || !agentEnabled(config.Agent) {
+		return false, ErrAgentDisabled
+	}
+
+	if err := s.AgentPing(ctx, node, vmid); err != nil {
+		return false, fmt.Errorf("%w: %v", ErrAgentNotResponding, err)
+	}
+
+	return true, nil
+}
+
+// agentEnabled parses the agent property string of a VM configuration
+func agentEnabled(agent string) bool {
+	for _, part := range strings.Split(agent, ",") {
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			value = key
+		} else if key != "enabled" {
+			continue
+		}
+		switch strings.TrimSpace(value) {
+		case "1", "on", "yes", "true":
+			return true
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+// agentCommand executes a guest agent command without a result
+func (s *QEMUService) agentCommand(ctx context.Context, method, node string, vmid int, command string, params map[string]any) error {
+	req, err := s.client.NewRequest(method, fmt.Sprintf("nodes/%s/qemu/%d/agent/%s", node, vmid, command), params, WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
+
+// AgentPing pings the QEMU guest agent
+func (s *QEMUService) AgentPing(ctx context.Context, node string, vmid int) error {
+	return s.agentCommand(ctx, "POST", node, vmid, "ping", nil)
+}
+
+// AgentFSFreeze freezes all guest filesystems and returns the number of frozen filesystems
+func (s *QEMUService) AgentFSFreeze(ctx context.Context, node string, vmid int) (int, error) {
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/qemu/%d/agent/fsfreeze-freeze", node, vmid), nil, WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		Data struct {
+			Result int `json:"result"`
+		} `json:"data"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.Data.Result, nil
+}
+
+// AgentFSThaw thaws all guest filesystems and returns the number of thawed filesystems
+func (s *QEMUService) AgentFSThaw(ctx context.Context, node string, vmid int) (int, error) {
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/qemu/%d/agent/fsfreeze-thaw", node, vmid), nil, WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		Data struct {
+			Result int `json:"result"`
+		} `json:"data"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.Data.Result, nil
+}
+
+// AgentFSFreezeStatus retrieves the guest filesystem freeze status ("thawed" or "frozen")
+func (s *QEMUService) AgentFSFreezeStatus(ctx context.Context, node string, vmid int) (string, error) {
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/qemu/%d/agent/fsfreeze-status", node, vmid), nil, WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Data struct {
+			Result string `json:"result"`
+		} `json:"data"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return "", err
+	}
+
+	return result.Data.Result, nil
+}
+
+// AgentFSTrim discards unused blocks on all guest filesystems
+func (s *QEMUService) AgentFSTrim(ctx context.Context, node string, vmid int) ([]GuestFSTrimResult, error) {
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/qemu/%d/agent/fstrim", node, vmid), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data struct {
+			Result struct {
+				Paths []GuestFSTrimResult `json:"paths"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data.Result.Paths, nil
+}
+
+// GetAgentOSInfo retrieves operating system information via QEMU guest agent
+func (s *QEMUService) GetAgentOSInfo(ctx context.Context, node string, vmid int) (*GuestOSInfo, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/get-osinfo", node, vmid), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data struct {
+			Result *GuestOSInfo `json:"result"`
+		} `json:"data"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data.Result, nil
+}
+
+// GetAgentHostName retrieves the guest host name via QEMU guest agent
+func (s *QEMUService) GetAgentHostName(ctx context.Context, node string, vmid int) (string, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/get-host-name", node, vmid), nil, WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Data struct {
+			Result struct {
+				HostName string `json:"host-name"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return "", err
+	}
+
+	return result.Data.Result.HostName, nil
+}
+
+// GetAgentTime retrieves the guest time via QEMU guest agent
+func (s *QEMUService) GetAgentTime(ctx context.Context, node string, vmid int) (time.Time, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/get-time", node, vmid), nil, WithContext(ctx))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var result struct {
+		Data struct {
+			Result int64 `json:"result"` // Nanoseconds since epoch
+		} `json:"data"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, result.Data.Result), nil
+}
+
+// GetAgentTimezone retrieves the guest timezone via QEMU guest agent
+func (s *QEMUService) GetAgentTimezone(ctx context.Context, node string, vmid int) (*GuestTimezone, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/get-timezone", node, vmid), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data struct {
+			Result *GuestTimezone `json:"result"`
+		} `json:"data"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data.Result, nil
+}
+
+// GetAgentUsers retrieves the users logged in to the guest via QEMU guest agent
+func (s *QEMUService) GetAgentUsers(ctx context.Context, node string, vmid int) ([]GuestUser, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/get-users", node, vmid), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data struct {
+			Result []GuestUser `json:"result"`
+		} `json:"data"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data.Result, nil
+}
+
+// GetAgentVCPUs retrieves the guest virtual CPUs via QEMU guest agent
+func (s *QEMUService) GetAgentVCPUs(ctx context.Context, node string, vmid int) ([]GuestVCPU, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/get-vcpus", node, vmid), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data struct {
+			Result []GuestVCPU `json:"result"`
+		} `json:"data"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data.Result, nil
+}
+
+// GetAgentMemoryBlocks retrieves the guest memory blocks via QEMU guest agent
+func (s *QEMUService) GetAgentMemoryBlocks(ctx context.Context, node string, vmid int) ([]GuestMemoryBlock, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/get-memory-blocks", node, vmid), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data struct {
+			Result []GuestMemoryBlock `json:"result"`
+		} `json:"data"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data.Result, nil
+}
+
+// SetAgentUserPassword sets the password of a guest user via QEMU guest agent.
+// Set crypted if the password has already been passed through crypt().
+func (s *QEMUService) SetAgentUserPassword(ctx context.Context, node string, vmid int, username, password string, crypted bool) error {
+	params := map[string]any{
+		"username": username,
+		"password": password,
+	}
+
+	if crypted {
+		params["crypted"] = 1
+	}
+
+	return s.agentCommand(ctx, "POST", node, vmid, "set-user-password", params)
+}
+
+// AgentShutdown shuts down the guest via QEMU guest agent
+func (s *QEMUService) AgentShutdown(ctx context.Context, node string, vmid int) error {
+	return s.agentCommand(ctx, "POST", node, vmid, "shutdown", nil)
+}
+
+// AgentSuspendDisk suspends the guest to disk via QEMU guest agent
+func (s *QEMUService) AgentSuspendDisk(ctx context.Context, node string, vmid int) error {
+	return s.agentCommand(ctx, "POST", node, vmid, "suspend-disk", nil)
+}
+
+// AgentSuspendRAM suspends the guest to RAM via QEMU guest agent
+func (s *QEMUService) AgentSuspendRAM(ctx context.Context, node string, vmid int) error {
+	return s.agentCommand(ctx, "POST", node, vmid, "suspend-ram", nil)
+}
+
+// AgentSuspendHybrid suspends the guest to disk and RAM via QEMU guest agent
+func (s *QEMUService) AgentSuspendHybrid(ctx context.Context, node string, vmid int) error {
+	return s.agentCommand(ctx, "POST", node, vmid, "suspend-hybrid", nil)
+}
@@ -0,0 +1,332 @@
+package pve
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// APTPackage represents a package in the APT update or version list of a node
+type APTPackage struct {
+	Package        string `json:"Package"`
+	Title          string `json:"Title"`
+	Description    string `json:"Description"`
+	Origin         string `json:"Origin"` // e.g. Proxmox or Debian
+	Section        string `json:"Section"`
+	Priority       string `json:"Priority"`
+	Arch           string `json:"Arch"`
+	Version        string `json:"Version"`      // Candidate version
+	OldVersion     string `json:"OldVersion"`   // Installed version
+	CurrentState   string `json:"CurrentState"` // Installed, NotInstalled, ... (versions only)
+	NotifyStatus   string `json:"NotifyStatus"`
+	ManagerVersion string `json:"ManagerVersion"` // Running pve-manager version (versions only)
+	RunningKernel  string `json:"RunningKernel"`  // Running kernel release (proxmox-ve only)
+}
+
+// Installed reports whether the package is installed
+func (p *APTPackage) Installed() bool {
+	return p.CurrentState == "Installed"
+}
+
+// APTRepositories holds the parsed APT repository configuration of a node
+type APTRepositories struct {
+	Digest        string                   `json:"digest"` // Digest of all files, used to detect concurrent changes
+	Files         []*APTRepositoryFile     `json:"files"`
+	Errors        []*APTRepositoryError    `json:"errors"`
+	Infos         []*APTRepositoryInfo     `json:"infos"`
+	StandardRepos []*APTStandardRepository `json:"standard-repos"`
+}
+
+// APTRepositoryFile is a parsed sources file
+type APTRepositoryFile struct {
+	Path         string           `json:"path"`
+	FileType     string           `json:"file-type"` // list or sources
+	Repositories []*APTRepository `json:"repositories"`
+}
+
+// APTRepository is a repository entry of a sources file. Its index within
+// the file's Repositories identifies it for SetRepositoryEnabled.
+type APTRepository struct {
+	Types      []string `json:"Types"` // deb, deb-src
+	URIs       []string `json:"URIs"`
+	Suites     []string `json:"Suites"`
+	Components []string `json:"Components"`
+	Options    []struct {
+		Key    string   `json:"Key"`
+		Values []string `json:"Values"`
+	} `json:"Options"`
+	Comment  string `json:"Comment"`
+	FileType string `json:"FileType"`
+	Enabled  bool   `json:"Enabled"`
+}
+
+// APTRepositoryError describes a sources file that could not be parsed
+type APTRepositoryError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// APTRepositoryInfo is a warning or hint about a repository
+type APTRepositoryInfo struct {
+	Path     string `json:"path"`
+	Index    string `json:"index"`
+	Kind     string `json:"kind"` // e.g. warning
+	Property string `json:"property"`
+	Message  string `json:"message"`
+}
+
+// APTStandardRepository is a Proxmox or Debian repository that can be added by handle
+type APTStandardRepository struct {
+	Handle string `json:"handle"` // e.g. no-subscription
+	Name   string `json:"name"`
+	Status *bool  `json:"status"` // nil if not configured
+}
+
+// RefreshPackages resynchronizes the package index of a node (apt-get update)
+func (s *NodesService) RefreshPackages(ctx context.Context, name string) (*Task, error) {
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/apt/update", name), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *Task
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// ListUpdates retrieves the upgradable packages of a node as of the last refresh
+func (s *NodesService) ListUpdates(ctx context.Context, name string) ([]*APTPackage, error) {
+	return s.aptPackages(ctx, name, "update")
+}
+
+// PackageVersions retrieves the versions of the important Proxmox packages of a node
+func (s *NodesService) PackageVersions(ctx context.Context, name string) ([]*APTPackage, error) {
+	return s.aptPackages(ctx, name, "versions")
+}
+
+// aptPackages retrieves a package list below nodes/{node}/apt
+func (s *NodesService) aptPackages(ctx context.Context, name, list string) ([]*APTPackage, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/apt/%s", name, list), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []*APTPackage
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// PackageChangelog retrieves the changelog of a package. version may be
+// empty for the candidate version.
+func (s *NodesService) PackageChangelog(ctx context.Context, name, pkg, version string) (string, error) {
+	params := map[string]string{
+		"name": pkg,
+	}
+	if version != "" {
+		params["version"] = version
+	}
+
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/apt/changelog", name), params, WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Data string
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return "", err
+	}
+
+	return result.Data, nil
+}
+
+// APTRepositories retrieves the APT repository configuration of a node
+func (s *NodesService) APTRepositories(ctx context.Context, name string) (*APTRepositories, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/apt/repositories", name), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *APTRepositories
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// SetRepositoryEnabled enables or disables the repository at index in the
+// sources file at path. If digest is set, the change fails when the
+// configuration was modified since it was read.
+func (s *NodesService) SetRepositoryEnabled(ctx context.Context, name, path string, index int, enabled bool, digest string) error {
+	params := map[string]any{
+		"path":    path,
+		"index":   index,
+		"enabled": enabled,
+	}
+	if digest != "" {
+		params["digest"] = digest
+	}
+
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/apt/repositories", name), params, WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
+
+// AddStandardRepository adds a standard repository by its handle, e.g.
+// no-subscription. If digest is set, the change fails when the configuration
+// was modified since it was read.
+func (s *NodesService) AddStandardRepository(ctx context.Context, name, handle, digest string) error {
+	params := map[string]string{
+		"handle": handle,
+	}
+	if digest != "" {
+		params["digest"] = digest
+	}
+
+	req, err := s.client.NewRequest("PUT", fmt.Sprintf("nodes/%s/apt/repositories", name), params, WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
+
+// RunningKernelPackage is the pseudo package under which PackageReport
+// compares the running kernels
+const RunningKernelPackage = "running-kernel"
+
+// PackageReport compares the installed Proxmox package versions of all nodes
+type PackageReport struct {
+	Nodes     []string             // Nodes whose versions were compared
+	Divergent []*PackageDivergence // Packages not installed in the same version everywhere
+	Errors    map[string]error     // Nodes whose versions could not be retrieved
+}
+
+// PackageDivergence lists the nodes per installed version of a package.
+// Nodes without the package are listed under an empty version.
+type PackageDivergence struct {
+	Package  string
+	Versions map[string][]string
+}
+
+// packageReportConcurrency is the number of nodes PackageReport queries in parallel
+const packageReportConcurrency = 4
+
+// PackageReport retrieves the package versions of all online nodes and
+// reports the packages whose installed version differs between nodes
+func (s *ClusterService) PackageReport(ctx context.Context) (*PackageReport, error) {
+	nodes, err := s.client.Nodes.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, packageReportConcurrency)
+		versions = map[string]map[string]string{} // node -> package -> version
+		report   = &PackageReport{Errors: map[string]error{}}
+	)
+	for _, node := range nodes {
+		if node.Status != "online" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+
+			var packages []*APTPackage
+			err := ctx.Err()
+			select {
+			case sem <- struct{}{}:
+				packages, err = s.client.Nodes.PackageVersions(ctx, name)
+				<-sem
+			case <-ctx.Done():
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Errors[name] = err
+				return
+			}
+			versions[name] = installedVersions(packages)
+		}(node.Name)
+	}
+	wg.Wait()
+
+	for name := range versions {
+		report.Nodes = append(report.Nodes, name)
+	}
+	sort.Strings(report.Nodes)
+	report.Divergent = packageDivergence(report.Nodes, versions)
+
+	return report, nil
+}
+
+// installedVersions maps the installed packages of a node to their version,
+// including the running kernel as RunningKernelPackage
+func installedVersions(packages []*APTPackage) map[string]string {
+	installed := map[string]string{}
+	for _, p := range packages {
+		if p.Installed() {
+			installed[p.Package] = p.OldVersion
+		}
+		if p.RunningKernel != "" {
+			installed[RunningKernelPackage] = p.RunningKernel
+		}
+	}
+
+	return installed
+}
+
+// packageDivergence returns the packages that are not installed in the same
+// version on all nodes, sorted by name. versions maps node -> package -> version.
+func packageDivergence(nodes []string, versions map[string]map[string]string) []*PackageDivergence {
+	names := map[string]bool{}
+	for _, installed := range versions {
+		for pkg := range installed {
+			names[pkg] = true
+		}
+	}
+
+	var divergent []*PackageDivergence
+	for pkg := range names {
+		byVersion := map[string][]string{}
+		for _, node := range nodes {
+			version := versions[node][pkg]
+			byVersion[version] = append(byVersion[version], node)
+		}
+		if len(byVersion) > 1 {
+			divergent = append(divergent, &PackageDivergence{Package: pkg, Versions: byVersion})
+		}
+	}
+	sort.Slice(divergent, func(i, j int) bool { return divergent[i].Package < divergent[j].Package })
+
+	return divergent
+}
@@ -0,0 +1,108 @@
+package pve
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInstalledVersions(t *testing.T) {
+	packages := []*APTPackage{
+		{Package: "proxmox-ve", OldVersion: "8.2.0", CurrentState: "Installed", RunningKernel: "6.8.4-2-pve"},
+		{Package: "pve-manager", OldVersion: "8.2.2", CurrentState: "Installed"},
+		{Package: "ceph", OldVersion: "", CurrentState: "NotInstalled"},
+	}
+
+	want := map[string]string{
+		"proxmox-ve":         "8.2.0",
+		"pve-manager":        "8.2.2",
+		RunningKernelPackage: "6.8.4-2-pve",
+	}
+	if got := installedVersions(packages); !reflect.DeepEqual(got, want) {
+		t.Errorf("installedVersions() = %v, want %v", got, want)
+	}
+}
+
+func TestPackageDivergence(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodes    []string
+		versions map[string]map[string]string
+		want     []*PackageDivergence
+	}{
+		{
+			name:  "same everywhere",
+			nodes: []string{"pve1", "pve2"},
+			versions: map[string]map[string]string{
+				"pve1": {"pve-manager": "8.2.2"},
+				"pve2": {"pve-manager": "8.2.2"},
+			},
+			want: nil,
+		},
+		{
+			name:  "different version",
+			nodes: []string{"pve1", "pve2", "pve3"},
+			versions: map[string]map[string]string{
+				"pve1": {"pve-manager": "8.2.2"},
+				"pve2": {"pve-manager": "8.2.4"},
+				"pve3": {"pve-manager": "8.2.2"},
+			},
+			want: []*PackageDivergence{
+				{Package: "pve-manager", Versions: map[string][]string{"8.2.2": {"pve1", "pve3"}, "8.2.4": {"pve2"}}},
+			},
+		},
+		{
+			name:  "missing on some nodes",
+			nodes: []string{"pve1", "pve2"},
+			versions: map[string]map[string]string{
+				"pve1": {"ceph": "18.2.2", "pve-manager": "8.2.2"},
+				"pve2": {"pve-manager": "8.2.2"},
+			},
+			want: []*PackageDivergence{
+				{Package: "ceph", Versions: map[string][]string{"18.2.2": {"pve1"}, "": {"pve2"}}},
+			},
+		},
+		{
+			name:  "running kernel",
+			nodes: []string{"pve1", "pve2"},
+			versions: map[string]map[string]string{
+				"pve1": {RunningKernelPackage: "6.8.4-2-pve", "proxmox-ve": "8.2.0"},
+				"pve2": {RunningKernelPackage: "6.5.13-5-pve", "proxmox-ve": "8.2.0"},
+			},
+			want: []*PackageDivergence{
+				{Package: RunningKernelPackage, Versions: map[string][]string{"6.8.4-2-pve": {"pve1"}, "6.5.13-5-pve": {"pve2"}}},
+			},
+		},
+		{
+			name:  "sorted by package",
+			nodes: []string{"pve1", "pve2"},
+			versions: map[string]map[string]string{
+				"pve1": {"qemu-server": "8.2.1", "corosync": "3.1.8"},
+				"pve2": {"qemu-server": "8.2.3", "corosync": "3.1.7"},
+			},
+			want: []*PackageDivergence{
+				{Package: "corosync", Versions: map[string][]string{"3.1.8": {"pve1"}, "3.1.7": {"pve2"}}},
+				{Package: "qemu-server", Versions: map[string][]string{"8.2.1": {"pve1"}, "8.2.3": {"pve2"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := packageDivergence(tt.nodes, tt.versions)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("packageDivergence() = %s, want %s", divergenceString(got), divergenceString(tt.want))
+			}
+		})
+	}
+}
+
+// divergenceString formats divergences for test failures
+func divergenceString(divergent []*PackageDivergence) string {
+	var parts []string
+	for _, d := range divergent {
+		parts = append(parts, fmt.Sprintf("%s:%v", d.Package, d.Versions))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,199 @@
+package pve
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BulkStartOptions specifies options for starting all guests of a node
+type BulkStartOptions struct {
+	Force bool  // Also start guests without onboot=1
+	VMIDs []int // Only start these guests
+}
+
+// BulkStopOptions specifies options for stopping all guests of a node
+type BulkStopOptions struct {
+	ForceStop bool  // Hard-stop guests that did not shut down within the timeout
+	Timeout   int   // Shutdown timeout in seconds for each guest
+	VMIDs     []int // Only stop these guests
+}
+
+// StartAll starts all guests of a node (by default only those with onboot=1)
+func (s *NodesService) StartAll(ctx context.Context, name string, options *BulkStartOptions) (*Task, error) {
+	params := map[string]any{}
+	if options != nil {
+		if options.Force {
+			params["force"] = 1
+		}
+		if len(options.VMIDs) > 0 {
+			params["vms"] = joinVMIDs(options.VMIDs)
+		}
+	}
+
+	return s.bulkAction(ctx, name, "startall", params)
+}
+
+// StopAll shuts down all guests of a node
+func (s *NodesService) StopAll(ctx context.Context, name string, options *BulkStopOptions) (*Task, error) {
+	params := map[string]any{}
+	if options != nil {
+		if options.ForceStop {
+			params["force-stop"] = 1
+		}
+		if options.Timeout > 0 {
+			params["timeout"] = options.Timeout
+		}
+		if len(options.VMIDs) > 0 {
+			params["vms"] = joinVMIDs(options.VMIDs)
+		}
+	}
+
+	return s.bulkAction(ctx, name, "stopall", params)
+}
+
+// SuspendAll suspends all VMs of a node, or only vmids if given
+func (s *NodesService) SuspendAll(ctx context.Context, name string, vmids ...int) (*Task, error) {
+	params := map[string]any{}
+	if len(vmids) > 0 {
+		params["vms"] = joinVMIDs(vmids)
+	}
+
+	return s.bulkAction(ctx, name, "suspendall", params)
+}
+
+// bulkAction posts a node-wide guest action (startall, stopall, ...)
+func (s *NodesService) bulkAction(ctx context.Context, name, action string, params map[string]any) (*Task, error) {
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s", name, action), params, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *Task
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// GuestBulkOptions specifies options for cluster-wide bulk guest actions
+type GuestBulkOptions struct {
+	VMIDs          []int  // Only consider these guests
+	MaxWorkers     int    // Maximum number of parallel tasks
+	Timeout        int    // Start or shutdown timeout in seconds (start, shutdown)
+	ForceStop      bool   // Stop guests that did not shut down within the timeout (shutdown)
+	ToDisk         bool   // Suspend to disk (suspend)
+	StateStorage   string // Storage for the VM state (suspend)
+	Online         bool   // Live migrate VMs and restart migrate containers (migrate)
+	WithLocalDisks bool   // Live migrate local disks (migrate)
+}
+
+// BulkStart starts or resumes guests across the cluster (PVE 8.4+)
+func (s *ClusterService) BulkStart(ctx context.Context, options *GuestBulkOptions) (*Task, error) {
+	params := guestBulkParams(options)
+	if options != nil && options.Timeout > 0 {
+		params["timeout"] = options.Timeout
+	}
+
+	return s.guestBulkAction(ctx, "start", params)
+}
+
+// BulkShutdown shuts down guests across the cluster (PVE 8.4+)
+func (s *ClusterService) BulkShutdown(ctx context.Context, options *GuestBulkOptions) (*Task, error) {
+	params := guestBulkParams(options)
+	if options != nil {
+		if options.Timeout > 0 {
+			params["timeout"] = options.Timeout
+		}
+		if options.ForceStop {
+			params["force-stop"] = 1
+		}
+	}
+
+	return s.guestBulkAction(ctx, "shutdown", params)
+}
+
+// BulkSuspend suspends guests across the cluster (PVE 8.4+)
+func (s *ClusterService) BulkSuspend(ctx context.Context, options *GuestBulkOptions) (*Task, error) {
+	params := guestBulkParams(options)
+	if options != nil {
+		if options.ToDisk {
+			params["to-disk"] = 1
+		}
+		if options.StateStorage != "" {
+			params["statestorage"] = options.StateStorage
+		}
+	}
+
+	return s.guestBulkAction(ctx, "suspend", params)
+}
+
+// BulkMigrate migrates guests across the cluster to target (PVE 8.4+)
+func (s *ClusterService) BulkMigrate(ctx context.Context, target string, options *GuestBulkOptions) (*Task, error) {
+	params := guestBulkParams(options)
+	params["target"] = target
+	if options != nil {
+		if options.Online {
+			params["online"] = 1
+		}
+		if options.WithLocalDisks {
+			params["with-local-disks"] = 1
+		}
+	}
+
+	return s.guestBulkAction(ctx, "migrate", params)
+}
+
+// guestBulkParams builds the parameters shared by all bulk guest actions
+func guestBulkParams(options *GuestBulkOptions) map[string]any {
+	params := map[string]any{}
+	if options == nil {
+		return params
+	}
+
+	if len(options.VMIDs) > 0 {
+		vms := make([]string, len(options.VMIDs))
+		for i, vmid := range options.VMIDs {
+			vms[i] = strconv.Itoa(vmid)
+		}
+		params["vms"] = vms
+	}
+	if options.MaxWorkers > 0 {
+		params["maxworkers"] = options.MaxWorkers
+	}
+
+	return params
+}
+
+// guestBulkAction posts a cluster-wide bulk guest action
+func (s *ClusterService) guestBulkAction(ctx context.Context, action string, params map[string]any) (*Task, error) {
+	req, err := s.client.NewRequest("POST", "cluster/bulk-action/guest/"+action, params, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *Task
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// joinVMIDs formats VMIDs as a comma-separated list
+func joinVMIDs(vmids []int) string {
+	ids := make([]string, len(vmids))
+	for i, vmid := range vmids {
+		ids[i] = strconv.Itoa(vmid)
+	}
+
+	return strings.Join(ids, ",")
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -26,6 +27,9 @@ const (
 	defaultTimeout   = 30 * time.Second
 	defaultRetries   = 3
 	defaultRateLimit = rate.Limit(10) // 10 requests per second
+
+	// Default lifetime of the cached vmid -> node mapping used by VMsService
+	defaultResolverTTL = 30 * time.Second
 )
 
 // AuthType represents authentication type
@@ -68,6 +72,9 @@ type Client struct {
 	// Rate limiting
 	limiter RateLimiter
 
+	// Lifetime of the VM location cache
+	resolverTTL time.Duration
+
 	// API services
 	Cluster *ClusterService
 	Nodes   *NodesService
@@ -120,6 +127,7 @@ func NewClient(baseURL string, authOptions *AuthOptions, options ...ClientOption
 		baseURL:     u,
 		authOptions: authOptions,
 		limiter:     rate.NewLimiter(defaultRateLimit, 1),
+		resolverTTL: defaultResolverTTL,
 		UserAgent:   userAgent,
 	}
 
@@ -139,7 +147,7 @@ func NewClient(baseURL string, authOptions *AuthOptions, options ...ClientOption
 	// Initialize services
 	c.Cluster = &ClusterService{client: c}
 	c.Nodes = &NodesService{client: c}
-	c.VMs = &VMsService{client: c}
+	c.VMs = &VMsService{client: c, ttl: c.resolverTTL}
 	c.QEMU = &QEMUService{client: c}
 	c.LXC = &LXCService{client: c}
 	c.Storage = &StorageService{client: c}
@@ -170,6 +178,15 @@ func WithRateLimiter(limiter RateLimiter) ClientOptionFunc {
 	}
 }
 
+// WithResolverTTL sets how long VMsService caches the node of a VM.
+// A TTL of 0 disables caching.
+func WithResolverTTL(ttl time.Duration) ClientOptionFunc {
+	return func(c *Client) error {
+		c.resolverTTL = ttl
+		return nil
+	}
+}
+
 // WithUserAgent sets a custom user agent
 func WithUserAgent(ua string) ClientOptionFunc {
 	return func(c *Client) error {
@@ -194,11 +211,13 @@ func (c *Client) NewRequest(method, path string, opt any, options ...RequestOpti
 
 	// Add query parameters
 	if opt != nil {
-		q, err := query.Values(opt)
+		q, err := encodeParams(opt)
 		if err != nil {
 			return nil, err
 		}
-		u += "?" + q.Encode()
+		if len(q) > 0 {
+			u += "?" + q.Encode()
+		}
 	}
 
 	// Create request
@@ -220,7 +239,8 @@ func (c *Client) NewRequest(method, path string, opt any, options ...RequestOpti
 	return req, nil
 }
 
-// Do executes an HTTP request
+// Do executes an HTTP request. A non-2xx response is returned together with
+// the error from ParseError.
 func (c *Client) Do(req *req.Request, v any) (*Response, error) {
 	// Rate limiting
 	if c.limiter != nil {
@@ -272,6 +292,11 @@ func (c *Client) Do(req *req.Request, v any) (*Response, error) {
 	body := resp.Bytes()
 	response.Body = body
 
+	// Check for API errors
+	if err := c.ParseError(response); err != nil {
+		return response, err
+	}
+
 	// Parse response
 	if v != nil && len(body) > 0 && resp.StatusCode != http.StatusNoContent {
 		if r, ok := v.(*[]byte); ok {
@@ -403,8 +428,8 @@ func (c *Client) ParseError(r *Response) error {
 	}
 
 	var errResult struct {
-		Errors []string `json:"errors"`
-		Data   string   `json:"data"`
+		Errors map[string]string `json:"errors"`
+		Data   any               `json:"data"`
 	}
 
 	if err := json.Unmarshal(r.Body, &errResult); err != nil {
@@ -412,16 +437,64 @@ func (c *Client) ParseError(r *Response) error {
 	}
 
 	if len(errResult.Errors) > 0 {
-		return errors.New(strings.Join(errResult.Errors, ", "))
+		msgs := make([]string, 0, len(errResult.Errors))
+		for param, msg := range errResult.Errors {
+			msgs = append(msgs, param+": "+strings.TrimSpace(msg))
+		}
+		sort.Strings(msgs)
+		return errors.New(strings.Join(msgs, ", "))
+	}
+
+	if data, ok := errResult.Data.(string); ok && data != "" {
+		return errors.New(data)
 	}
 
-	if errResult.Data != "" {
-		return errors.New(errResult.Data)
+	// PVE reports most failures in the HTTP reason phrase only
+	if reason := strings.TrimSpace(strings.TrimPrefix(r.Status, strconv.Itoa(r.StatusCode))); reason != "" {
+		return fmt.Errorf("API error (status %d): %s", r.StatusCode, reason)
 	}
 
 	return fmt.Errorf("API error (status %d)", r.StatusCode)
 }
 
+// encodeParams converts request parameters to URL values.
+// Structs are encoded with go-querystring, maps are encoded directly
+// with booleans as 0/1 and slices as repeated keys.
+func encodeParams(opt any) (url.Values, error) {
+	switch params := opt.(type) {
+	case url.Values:
+		return params, nil
+	case map[string]string:
+		values := url.Values{}
+		for k, v := range params {
+			values.Set(k, v)
+		}
+		return values, nil
+	case map[string]any:
+		values := url.Values{}
+		for k, v := range params {
+			switch val := v.(type) {
+			case nil:
+			case bool:
+				if val {
+					values.Set(k, "1")
+				} else {
+					values.Set(k, "0")
+				}
+			case []string:
+				for _, item := range val {
+					values.Add(k, item)
+				}
+			default:
+				values.Set(k, fmt.Sprint(val))
+			}
+		}
+		return values, nil
+	default:
+		return query.Values(opt)
+	}
+}
+
 // parseID converts various ID types to string
 func parseID(id any) (string, error) {
 	switch v := id.(type) {
@@ -437,3 +510,11 @@ func parseID(id any) (string, error) {
 // RequestOptionFunc is a function that can modify a request
 // Note: Using req.Request instead of http.Request
 type RequestOptionFunc func(*req.Request) error
+
+// WithContext sets the context used for a request
+func WithContext(ctx context.Context) RequestOptionFunc {
+	return func(r *req.Request) error {
+		r.SetContext(ctx)
+		return nil
+	}
+}
@@ -0,0 +1,46 @@
+package pve
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		code   int
+		body   string
+		want   string
+	}{
+		{"success", "200 OK", 200, `{"data":null}`, ""},
+		{"no content", "204 No Content", 204, "", ""},
+		{"parameter errors", "400 Parameter verification failed.", 400,
+			`{"errors":{"vmid":"invalid format ","node":"missing"},"data":null}`,
+			"node: missing, vmid: invalid format"},
+		{"data message", "500 Internal Server Error", 500, `{"data":"storage is locked"}`, "storage is locked"},
+		{"reason phrase", "500 Configuration file 'nodes/pve1/qemu-server/100.conf' does not exist", 500,
+			`{"data":null}`,
+			"API error (status 500): Configuration file 'nodes/pve1/qemu-server/100.conf' does not exist"},
+		{"status only", "401", 401, `{"data":null}`, "API error (status 401)"},
+		{"not json", "502 Bad Gateway", 502, "proxy error", "API error (status 502): proxy error"},
+	}
+
+	c := &Client{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Response{
+				Response: &http.Response{Status: tt.status, StatusCode: tt.code},
+				Body:     []byte(tt.body),
+			}
+			err := c.ParseError(r)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("ParseError() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,26 @@
 package pve
 
+import (
+	"context"
+	"encoding/json"
+)
+
 // ClusterService handles cluster-related API operations
 type ClusterService struct {
 	client *Client
 }
 
+// UnmarshalJSON decodes cluster information and fills the deprecated VersionInfo
+func (c *Cluster) UnmarshalJSON(data []byte) error {
+	type plain Cluster
+	if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+		return err
+	}
+	c.VersionInfo.Version = c.Version
+
+	return nil
+}
+
 // Get retrieves cluster information
 func (s *ClusterService) Get() (*Cluster, error) {
 	req, err := s.client.NewRequest("GET", "cluster", nil)
@@ -21,27 +37,99 @@ func (s *ClusterService) Get() (*Cluster, error) {
 	return cluster, nil
 }
 
-// Resources retrieves all cluster resources
-func (s *ClusterService) Resources() ([]*ClusterResource, error) {
-	req, err := s.client.NewRequest("GET", "cluster/resources", nil)
-	if err != nil {
+// ResourceType is a resource type filter accepted by cluster/resources
+type ResourceType string
+
+const (
+	ResourceTypeAll     ResourceType = ""
+	ResourceTypeVM      ResourceType = "vm"
+	ResourceTypeStorage ResourceType = "storage"
+	ResourceTypeNode    ResourceType = "node"
+	ResourceTypeSDN     ResourceType = "sdn"
+)
+
+// Resources retrieves cluster resources, filtered server-side by type.
+// Use ResourceTypeAll to retrieve every resource.
+func (s *ClusterService) Resources(ctx context.Context, resourceType ResourceType) ([]*ClusterResource, error) {
+	var resources []*ClusterResource
+	if err := s.resources(ctx, resourceType, &resources); err != nil {
 		return nil, err
 	}
 
-	var result struct {
-		Data []*ClusterResource
+	return resources, nil
+}
+
+// resources retrieves cluster resources of a type and decodes them into v
+func (s *ClusterService) resources(ctx context.Context, resourceType ResourceType, v any) error {
+	params := map[string]string{}
+	if resourceType != ResourceTypeAll {
+		params["type"] = string(resourceType)
 	}
-	_, err = s.client.Do(req, &result)
+
+	req, err := s.client.NewRequest("GET", "cluster/resources", params, WithContext(ctx))
 	if err != nil {
+		return err
+	}
+
+	result := struct {
+		Data any `json:"data"`
+	}{Data: v}
+	_, err = s.client.Do(req, &result)
+	return err
+}
+
+// VMResources retrieves all QEMU VMs and LXC containers in the cluster
+func (s *ClusterService) VMResources(ctx context.Context) (VMResources, error) {
+	var resources VMResources
+	if err := s.resources(ctx, ResourceTypeVM, &resources); err != nil {
 		return nil, err
 	}
 
-	return result.Data, nil
+	return resources, nil
+}
+
+// StorageResources retrieves all storages of all nodes in the cluster
+func (s *ClusterService) StorageResources(ctx context.Context) ([]*StorageResource, error) {
+	var resources []*StorageResource
+	if err := s.resources(ctx, ResourceTypeStorage, &resources); err != nil {
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+// NodeResources retrieves all nodes in the cluster
+func (s *ClusterService) NodeResources(ctx context.Context) ([]*NodeResource, error) {
+	var resources []*NodeResource
+	if err := s.resources(ctx, ResourceTypeNode, &resources); err != nil {
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+// SDNResources retrieves all SDN zones in the cluster
+func (s *ClusterService) SDNResources(ctx context.Context) ([]*SDNResource, error) {
+	var resources []*SDNResource
+	if err := s.resources(ctx, ResourceTypeSDN, &resources); err != nil {
+		return nil, err
+	}
+
+	return resources, nil
 }
 
-// ResourcesByType retrieves cluster resources filtered by type
+// ResourcesByType retrieves cluster resources filtered by resource type
+// (qemu, lxc, storage, node, sdn, pool, ...)
 func (s *ClusterService) ResourcesByType(resourceType string) ([]*ClusterResource, error) {
-	resources, err := s.Resources()
+	filter := ResourceTypeAll
+	switch resourceType {
+	case "qemu", "lxc":
+		filter = ResourceTypeVM
+	case "storage", "node", "sdn":
+		filter = ResourceType(resourceType)
+	}
+
+	resources, err := s.Resources(context.Background(), filter)
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +144,78 @@ func (s *ClusterService) ResourcesByType(resourceType string) ([]*ClusterResourc
 	return filtered, nil
 }
 
+// VMResources represents a list of guests that can be queried client-side
+type VMResources []*VMResource
+
+// Filter returns the guests for which keep returns true
+func (r VMResources) Filter(keep func(*VMResource) bool) VMResources {
+	var filtered VMResources
+	for _, vm := range r {
+		if keep(vm) {
+			filtered = append(filtered, vm)
+		}
+	}
+
+	return filtered
+}
+
+// WithTag returns the guests that have the given tag
+func (r VMResources) WithTag(tag string) VMResources {
+	return r.Filter(func(vm *VMResource) bool {
+		return vm.Tags.Has(tag)
+	})
+}
+
+// InPool returns the guests that are members of the given pool
+func (r VMResources) InPool(pool string) VMResources {
+	return r.Filter(func(vm *VMResource) bool {
+		return vm.Pool == pool
+	})
+}
+
+// Templates returns the guests that are templates
+func (r VMResources) Templates() VMResources {
+	return r.Filter(func(vm *VMResource) bool {
+		return vm.Template == 1
+	})
+}
+
+// Guests returns the guests that are not templates
+func (r VMResources) Guests() VMResources {
+	return r.Filter(func(vm *VMResource) bool {
+		return vm.Template != 1
+	})
+}
+
+// WithHAState returns the guests with the given HA state (started, stopped, ...).
+// An empty state matches guests that are not HA managed.
+func (r VMResources) WithHAState(state string) VMResources {
+	return r.Filter(func(vm *VMResource) bool {
+		return vm.HAState == state
+	})
+}
+
+// WithStatus returns the guests with the given status (running, stopped, ...)
+func (r VMResources) WithStatus(status string) VMResources {
+	return r.Filter(func(vm *VMResource) bool {
+		return vm.Status == status
+	})
+}
+
+// OnNode returns the guests located on the given node
+func (r VMResources) OnNode(node string) VMResources {
+	return r.Filter(func(vm *VMResource) bool {
+		return vm.Node == node
+	})
+}
+
+// OfType returns the guests of the given type (qemu or lxc)
+func (r VMResources) OfType(vmType string) VMResources {
+	return r.Filter(func(vm *VMResource) bool {
+		return vm.Type == vmType
+	})
+}
+
 // GetResource retrieves a specific cluster resource
 func (s *ClusterService) GetResource(resourceID string) (*ClusterResource, error) {
 	req, err := s.client.NewRequest("GET", "cluster/resources/"+resourceID, nil)
@@ -104,7 +264,12 @@ func (s *ClusterService) Tasks() ([]*Task, error) {
 
 // Status retrieves cluster status and node list
 func (s *ClusterService) Status() ([]*ClusterStatus, error) {
-	req, err := s.client.NewRequest("GET", "cluster/status", nil)
+	return s.status(context.Background())
+}
+
+// status retrieves cluster status and node list within ctx
+func (s *ClusterService) status(ctx context.Context) ([]*ClusterStatus, error) {
+	req, err := s.client.NewRequest("GET", "cluster/status", nil, WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
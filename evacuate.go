@@ -0,0 +1,407 @@
+package pve
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MigrateAllOptions specifies options for migrating all guests of a node
+type MigrateAllOptions struct {
+	MaxWorkers     int   // Maximum number of parallel migrations, 0 uses max_workers from datacenter.cfg which must then be set
+	VMIDs          []int // Only migrate these guests
+	WithLocalDisks bool  // Live migrate local disks
+}
+
+// MigrateAll migrates all VMs and containers of a node to target
+func (s *NodesService) MigrateAll(ctx context.Context, name, target string, options *MigrateAllOptions) (*Task, error) {
+	params := map[string]any{
+		"target": target,
+	}
+
+	if options != nil {
+		if options.MaxWorkers > 0 {
+			params["maxworkers"] = options.MaxWorkers
+		}
+		if len(options.VMIDs) > 0 {
+			params["vms"] = joinVMIDs(options.VMIDs)
+		}
+		if options.WithLocalDisks {
+			params["with-local-disks"] = 1
+		}
+	}
+
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/migrateall", name), params, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *Task
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// EvacuateOptions specifies how guests are moved off a node
+type EvacuateOptions struct {
+	Target         string // Target node for all guests (ignored if PickTarget is set)
+	MaxParallel    int    // Maximum number of parallel migrations (default 1, also with UseMigrateAll)
+	Skip           []int  // Guests to leave on the node
+	SkipStopped    bool   // Leave stopped guests and templates on the node
+	WithLocalDisks bool   // Live migrate local disks of running QEMU VMs
+	RestartTimeout int    // Shutdown timeout in seconds for restart migration of running containers
+	SkipHAManaged  bool   // Leave HA managed guests on the node, reported as skipped
+	UseMigrateAll  bool   // Use the node's migrateall endpoint, requires Target
+
+	// PickTarget chooses the target node of a guest among the other online
+	// nodes. If neither PickTarget nor Target is set, the node with the most
+	// free memory is chosen.
+	PickTarget func(guest *VMResource, candidates []*NodeResource) (string, error)
+}
+
+// GuestMigration describes the migration of a single guest
+type GuestMigration struct {
+	VMID    int
+	Type    string // qemu or lxc
+	Name    string
+	Status  string // Guest status when the migration was planned
+	HAState string // HA state, empty if the guest is not HA managed
+	Source  string
+	Target  string
+	Task    *Task  // Migration task, shared by all guests when migrateall is used
+	Reason  string // Why the guest was skipped
+	Err     error
+}
+
+// EvacuationReport summarizes an evacuation or restore
+type EvacuationReport struct {
+	Node     string // Evacuated node
+	Migrated []*GuestMigration
+	Skipped  []*GuestMigration
+	Failed   []*GuestMigration
+	Duration time.Duration
+}
+
+// Evacuate migrates the guests of a node to other nodes of the cluster, e.g.
+// before maintenance. Running VMs are live migrated, running containers use
+// restart migration. The report lists migrated, skipped and failed guests and
+// is returned even if some migrations failed. Migrations of HA managed guests
+// complete asynchronously and are waited for until ctx is done. With
+// SkipHAManaged these guests keep running on the node. The API has no endpoint
+// for the HA node maintenance mode; to let HA move them, enable it with
+// ha-manager crm-command node-maintenance instead.
+func (s *NodesService) Evacuate(ctx context.Context, node string, opts *EvacuateOptions) (*EvacuationReport, error) {
+	if opts == nil {
+		opts = &EvacuateOptions{}
+	}
+	if opts.UseMigrateAll && (opts.Target == "" || opts.PickTarget != nil) {
+		return nil, fmt.Errorf("migrateall requires a fixed target node")
+	}
+
+	start := time.Now()
+	report := &EvacuationReport{Node: node}
+
+	guests, err := s.client.Cluster.VMResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := s.client.Cluster.NodeResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*NodeResource
+	for _, n := range nodes {
+		if n.Status == "online" && n.Node != node {
+			candidates = append(candidates, n)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no online node to evacuate %s to", node)
+	}
+
+	// Track free memory so that the default picker spreads guests
+	free := map[string]int64{}
+	for _, n := range candidates {
+		free[n.Node] = n.MaxMem - n.Mem
+	}
+
+	guests = guests.OnNode(node)
+	sort.Slice(guests, func(i, j int) bool { return guests[i].VMID < guests[j].VMID })
+
+	var planned []*GuestMigration
+	for _, guest := range guests {
+		m := &GuestMigration{
+			VMID:    guest.VMID,
+			Type:    guest.Type,
+			Name:    guest.Name,
+			Status:  guest.Status,
+			HAState: guest.HAState,
+			Source:  node,
+		}
+
+		switch {
+		case slices.Contains(opts.Skip, guest.VMID):
+			m.Reason = "in skip list"
+		case opts.SkipStopped && guest.Status != "running":
+			m.Reason = "not running"
+		case opts.SkipHAManaged && guest.HAState != "":
+			m.Reason = "managed by HA, left on node"
+		}
+		if m.Reason != "" {
+			report.Skipped = append(report.Skipped, m)
+			continue
+		}
+
+		switch {
+		case opts.PickTarget != nil:
+			m.Target, m.Err = opts.PickTarget(guest, candidates)
+		case opts.Target != "":
+			m.Target = opts.Target
+		default:
+			for _, n := range candidates {
+				if m.Target == "" || free[n.Node] > free[m.Target] {
+					m.Target = n.Node
+				}
+			}
+			free[m.Target] -= guest.MaxMem
+		}
+		if m.Err == nil && m.Target == "" {
+			m.Err = fmt.Errorf("no target node for %s %d", guest.Type, guest.VMID)
+		}
+		if m.Err != nil {
+			report.Failed = append(report.Failed, m)
+			continue
+		}
+
+		planned = append(planned, m)
+	}
+
+	if opts.UseMigrateAll {
+		s.client.migrateAll(ctx, node, planned, opts, report)
+	} else {
+		s.client.runMigrations(ctx, planned, opts, report)
+	}
+
+	report.Duration = time.Since(start)
+	return report, report.err()
+}
+
+// Restore moves the guests migrated by an evacuation back to the evacuated
+// node. Only MaxParallel, WithLocalDisks and RestartTimeout of opts are used.
+func (s *NodesService) Restore(ctx context.Context, evacuation *EvacuationReport, opts *EvacuateOptions) (*EvacuationReport, error) {
+	if opts == nil {
+		opts = &EvacuateOptions{}
+	}
+
+	start := time.Now()
+	report := &EvacuationReport{Node: evacuation.Node}
+
+	s.client.VMs.InvalidateResource(0)
+
+	var planned []*GuestMigration
+	for _, migrated := range evacuation.Migrated {
+		m := &GuestMigration{
+			VMID:   migrated.VMID,
+			Type:   migrated.Type,
+			Name:   migrated.Name,
+			Target: evacuation.Node,
+		}
+
+		vm, err := s.client.VMs.ResolveVM(ctx, migrated.VMID)
+		if err != nil {
+			m.Err = err
+			report.Failed = append(report.Failed, m)
+			continue
+		}
+
+		m.Source, m.Status, m.HAState = vm.Node, vm.Status, migrated.HAState
+		if vm.Node == evacuation.Node {
+			m.Reason = "already on node"
+			report.Skipped = append(report.Skipped, m)
+			continue
+		}
+
+		planned = append(planned, m)
+	}
+
+	s.client.runMigrations(ctx, planned, opts, report)
+
+	report.Duration = time.Since(start)
+	return report, report.err()
+}
+
+// err summarizes the failed migrations of a report
+func (r *EvacuationReport) err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%d of %d guests failed to migrate", len(r.Failed), len(r.Failed)+len(r.Migrated))
+}
+
+// runMigrations migrates guests one by one, running up to opts.MaxParallel at once
+func (c *Client) runMigrations(ctx context.Context, planned []*GuestMigration, opts *EvacuateOptions, report *EvacuationReport) {
+	parallel := opts.MaxParallel
+	if parallel <= 0 {
+		parallel = 1
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, parallel)
+	)
+
+	finish := func(m *GuestMigration) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if m.Err != nil {
+			report.Failed = append(report.Failed, m)
+		} else {
+			report.Migrated = append(report.Migrated, m)
+		}
+	}
+
+	for _, m := range planned {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			m.Err = ctx.Err()
+			finish(m)
+			continue
+		}
+
+		wg.Add(1)
+		go func(m *GuestMigration) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			m.Err = c.migrateGuest(ctx, m, opts)
+			finish(m)
+		}(m)
+	}
+	wg.Wait()
+
+	byVMID := func(list []*GuestMigration) {
+		sort.Slice(list, func(i, j int) bool { return list[i].VMID < list[j].VMID })
+	}
+	byVMID(report.Migrated)
+	byVMID(report.Failed)
+}
+
+// migrateGuest migrates a single guest and waits until it runs on the target
+func (c *Client) migrateGuest(ctx context.Context, m *GuestMigration, opts *EvacuateOptions) error {
+	options := &MigrateOptions{}
+	if m.Status == "running" {
+		if m.Type == "qemu" {
+			options.Online = true
+			options.WithLocalDisks = opts.WithLocalDisks
+		} else {
+			options.Restart = true
+			options.Timeout = opts.RestartTimeout
+		}
+	}
+
+	var err error
+	m.Task, err = c.migrate(ctx, m.Source, m.Type, m.VMID, m.Target, options)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Tasks.Wait(ctx, m.Task); err != nil {
+		return err
+	}
+
+	// The task of an HA managed guest only queues the request for the HA manager
+	if m.HAState != "" {
+		return c.waitGuestNode(ctx, m.VMID, m.Target)
+	}
+
+	return nil
+}
+
+// migrateAll migrates the planned guests with a single migrateall task and
+// checks where each guest ended up
+func (c *Client) migrateAll(ctx context.Context, node string, planned []*GuestMigration, opts *EvacuateOptions, report *EvacuationReport) {
+	if len(planned) == 0 {
+		return
+	}
+
+	options := &MigrateAllOptions{
+		MaxWorkers:     opts.MaxParallel,
+		WithLocalDisks: opts.WithLocalDisks,
+	}
+	// MaxParallel defaults to 1 rather than datacenter.cfg, as without migrateall
+	if options.MaxWorkers <= 0 {
+		options.MaxWorkers = 1
+	}
+	for _, m := range planned {
+		options.VMIDs = append(options.VMIDs, m.VMID)
+	}
+
+	task, taskErr := c.Nodes.MigrateAll(ctx, node, opts.Target, options)
+	if taskErr == nil {
+		task, taskErr = c.Tasks.Wait(ctx, task)
+	}
+
+	c.VMs.InvalidateResource(0)
+
+	for _, m := range planned {
+		m.Task = task
+
+		switch {
+		case task == nil:
+			m.Err = taskErr
+		case m.HAState != "":
+			m.Err = c.waitGuestNode(ctx, m.VMID, m.Target)
+		default:
+			vm, err := c.VMs.ResolveVM(ctx, m.VMID)
+			if err != nil {
+				m.Err = err
+			} else if vm.Node != m.Target {
+				m.Err = fmt.Errorf("%s %d is still on %s", m.Type, m.VMID, vm.Node)
+				if taskErr != nil {
+					m.Err = fmt.Errorf("%w: %w", m.Err, taskErr)
+				}
+			}
+		}
+
+		if m.Err != nil {
+			report.Failed = append(report.Failed, m)
+		} else {
+			report.Migrated = append(report.Migrated, m)
+		}
+	}
+}
+
+// waitGuestNode polls the cluster resources until a guest is located on node
+func (c *Client) waitGuestNode(ctx context.Context, vmid int, node string) error {
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+
+	for {
+		c.VMs.InvalidateResource(vmid)
+		vm, err := c.VMs.ResolveVM(ctx, vmid)
+		if err != nil {
+			return err
+		}
+		if vm.Node == node {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package pve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultExpectTimeout is the time Expect waits for a match unless ctx ends earlier
+	defaultExpectTimeout = time.Minute
+	// defaultExpectBuffer is the amount of unmatched output kept for matching
+	defaultExpectBuffer = 64 << 10
+)
+
+var (
+	// ErrExpectTimeout is returned when the expected output did not appear in time
+	ErrExpectTimeout = errors.New("timed out waiting for expected output")
+	// ErrExpecterClosed is returned by Expect after Close
+	ErrExpecterClosed = errors.New("expecter closed")
+)
+
+// Expecter drives an interactive session such as a serial console by waiting
+// for output patterns and sending input.
+//
+// The caller owns the lifetime of both the Expecter and rw: call Close when
+// done and close rw, the reader goroutine exits once its pending Read returns.
+type Expecter struct {
+	Timeout    time.Duration // Time Expect waits for a match (default 1m)
+	BufferSize int           // Unmatched output kept for matching, older output is dropped (default 64KiB)
+	Log        io.Writer     // Receives a copy of all output (optional)
+
+	rw        io.ReadWriter
+	chunks    chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.Mutex
+	err       error  // read error, set once the reader stopped
+	buf       []byte // output not consumed by a match yet
+}
+
+// NewExpecter creates an Expecter on rw and starts reading its output
+func NewExpecter(rw io.ReadWriter) *Expecter {
+	e := &Expecter{
+		Timeout:    defaultExpectTimeout,
+		BufferSize: defaultExpectBuffer,
+		rw:         rw,
+		chunks:     make(chan []byte, 16),
+		done:       make(chan struct{}),
+	}
+	go e.read()
+
+	return e
+}
+
+// Close stops forwarding output. It does not close rw.
+func (e *Expecter) Close() error {
+	e.closeOnce.Do(func() { close(e.done) })
+	return nil
+}
+
+// read forwards output chunks until rw fails or the Expecter is closed
+func (e *Expecter) read() {
+	defer close(e.chunks)
+
+	for {
+		p := make([]byte, 4096)
+		n, err := e.rw.Read(p)
+		if n > 0 {
+			select {
+			case e.chunks <- p[:n]:
+			case <-e.done:
+				return
+			}
+		}
+		if err != nil {
+			e.mu.Lock()
+			e.err = err
+			e.mu.Unlock()
+			return
+		}
+	}
+}
+
+// Expect waits until re matches the output and returns the match and its
+// submatches. Output up to the end of the match is consumed.
+func (e *Expecter) Expect(ctx context.Context, re *regexp.Regexp) ([]string, error) {
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = defaultExpectTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		if loc := re.FindSubmatchIndex(e.buf); loc != nil {
+			match := make([]string, len(loc)/2)
+			for i := range match {
+				if loc[2*i] >= 0 {
+					match[i] = string(e.buf[loc[2*i]:loc[2*i+1]])
+				}
+			}
+			e.buf = e.buf[loc[1]:]
+			return match, nil
+		}
+
+		select {
+		case chunk, ok := <-e.chunks:
+			if !ok {
+				e.mu.Lock()
+				err := e.err
+				e.mu.Unlock()
+				return nil, fmt.Errorf("waiting for %q: %w", re, err)
+			}
+			if e.Log != nil {
+				e.Log.Write(chunk)
+			}
+			e.buf = append(e.buf, chunk...)
+			if limit := e.bufferSize(); len(e.buf) > limit {
+				e.buf = append([]byte(nil), e.buf[len(e.buf)-limit:]...)
+			}
+		case <-e.done:
+			return nil, fmt.Errorf("waiting for %q: %w", re, ErrExpecterClosed)
+		case <-timer.C:
+			return nil, fmt.Errorf("waiting for %q: %w", re, ErrExpectTimeout)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// bufferSize returns the configured BufferSize or its default
+func (e *Expecter) bufferSize() int {
+	if e.BufferSize <= 0 {
+		return defaultExpectBuffer
+	}
+	return e.BufferSize
+}
+
+// ExpectString waits until s appears in the output
+func (e *Expecter) ExpectString(ctx context.Context, s string) error {
+	_, err := e.Expect(ctx, regexp.MustCompile(regexp.QuoteMeta(s)))
+	return err
+}
+
+// Send writes s as is
+func (e *Expecter) Send(s string) error {
+	_, err := io.WriteString(e.rw, s)
+	return err
+}
+
+// SendLine writes line followed by a carriage return, the Enter key of a terminal
+func (e *Expecter) SendLine(line string) error {
+	return e.Send(line + "\r")
+}
+
+// Buffered returns the output received but not consumed by a match yet
+func (e *Expecter) Buffered() string {
+	return string(e.buf)
+}
@@ -0,0 +1,186 @@
+package pve
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// pipeConn is an io.ReadWriter whose output is written by the test
+type pipeConn struct {
+	io.Reader
+	io.Writer
+}
+
+func newPipeExpecter(t *testing.T) (*Expecter, *io.PipeWriter) {
+	t.Helper()
+	pr, pw := io.Pipe()
+	e := NewExpecter(pipeConn{pr, io.Discard})
+	t.Cleanup(func() {
+		e.Close()
+		pw.Close()
+	})
+	return e, pw
+}
+
+func TestExpect(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   []string
+		pattern  string
+		want     []string
+		buffered string
+	}{
+		{"single chunk", []string{"login: "}, `login:`, []string{"login:"}, " "},
+		{"split across chunks", []string{"Pass", "word: "}, `Password:`, []string{"Password:"}, " "},
+		{"submatch", []string{"root@pve1:~# "}, `root@(\S+):~#`, []string{"root@pve1:~#", "pve1"}, " "},
+		{"optional group", []string{"ok\n"}, `(fail)?ok`, []string{"ok", ""}, "\n"},
+		{"first match", []string{"a1 a2"}, `a\d`, []string{"a1"}, " a2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, pw := newPipeExpecter(t)
+			go func() {
+				for _, chunk := range tt.output {
+					pw.Write([]byte(chunk))
+				}
+			}()
+
+			got, err := e.Expect(context.Background(), regexp.MustCompile(tt.pattern))
+			if err != nil {
+				t.Fatalf("Expect() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expect() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expect() = %q, want %q", got, tt.want)
+				}
+			}
+			if b := e.Buffered(); b != tt.buffered {
+				t.Errorf("Buffered() = %q, want %q", b, tt.buffered)
+			}
+		})
+	}
+}
+
+func TestExpectString(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		s      string
+	}{
+		{"plain", "login: ", "login:"},
+		{"regexp characters", "[root@pve1 ~]$ ", "[root@pve1 ~]$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, pw := newPipeExpecter(t)
+			go pw.Write([]byte(tt.output))
+
+			if err := e.ExpectString(context.Background(), tt.s); err != nil {
+				t.Errorf("ExpectString(%q) error = %v", tt.s, err)
+			}
+		})
+	}
+}
+
+func TestExpectErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(e *Expecter, pw *io.PipeWriter) error
+		want error
+	}{
+		{
+			name: "timeout",
+			run: func(e *Expecter, pw *io.PipeWriter) error {
+				e.Timeout = 50 * time.Millisecond
+				go pw.Write([]byte("something else"))
+				return e.ExpectString(context.Background(), "login:")
+			},
+			want: ErrExpectTimeout,
+		},
+		{
+			name: "context done",
+			run: func(e *Expecter, pw *io.PipeWriter) error {
+				ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				defer cancel()
+				return e.ExpectString(ctx, "login:")
+			},
+			want: context.DeadlineExceeded,
+		},
+		{
+			name: "output closed",
+			run: func(e *Expecter, pw *io.PipeWriter) error {
+				pw.Close()
+				return e.ExpectString(context.Background(), "login:")
+			},
+			want: io.EOF,
+		},
+		{
+			name: "expecter closed",
+			run: func(e *Expecter, pw *io.PipeWriter) error {
+				e.Close()
+				return e.ExpectString(context.Background(), "login:")
+			},
+			want: ErrExpecterClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, pw := newPipeExpecter(t)
+			if err := tt.run(e, pw); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectBufferSize(t *testing.T) {
+	e, pw := newPipeExpecter(t)
+	e.BufferSize = 8
+	e.Timeout = 50 * time.Millisecond
+	go pw.Write([]byte("0123456789abcdef"))
+
+	if err := e.ExpectString(context.Background(), "login:"); !errors.Is(err, ErrExpectTimeout) {
+		t.Fatalf("ExpectString() error = %v, want %v", err, ErrExpectTimeout)
+	}
+	if b := e.Buffered(); b != "89abcdef" {
+		t.Errorf("Buffered() = %q, want %q", b, "89abcdef")
+	}
+}
+
+func TestExpecterCloseStopsReader(t *testing.T) {
+	e, pw := newPipeExpecter(t)
+	e.Close()
+
+	// More chunks than the channel holds, nobody calls Expect
+	go func() {
+		for i := 0; i < 32; i++ {
+			if _, err := pw.Write([]byte("x")); err != nil {
+				return
+			}
+		}
+	}()
+
+	// The reader closes the channel once it exits
+	stopped := make(chan struct{})
+	go func() {
+		for range e.chunks {
+		}
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("reader still running after Close")
+	}
+}
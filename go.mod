@@ -6,6 +6,7 @@ require (
 	github.com/davecgh/go-spew v1.1.1
 	github.com/google/go-querystring v1.1.0
 	github.com/imroc/req/v3 v3.56.0
+	golang.org/x/net v0.47.0
 	golang.org/x/time v0.14.0
 )
 
@@ -17,7 +18,6 @@ require (
 	github.com/quic-go/quic-go v0.56.0 // indirect
 	github.com/refraction-networking/utls v1.8.1 // indirect
 	golang.org/x/crypto v0.44.0 // indirect
-	golang.org/x/net v0.47.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
 )
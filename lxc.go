@@ -1,6 +1,7 @@
 package pve
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -219,42 +220,7 @@ func (s *LXCService) Delete(node string, vmid int) (*Task, error) {
 
 // Migrate migrates an LXC container to another node
 func (s *LXCService) Migrate(node string, vmid int, target string, options *MigrateOptions) (*Task, error) {
-	params := map[string]any{
-		"target": target,
-	}
-
-	if options != nil {
-		if options.Online {
-			params["online"] = 1
-		}
-		if options.Force {
-			params["force"] = 1
-		}
-		if options.BWLimit > 0 {
-			params["bwlimit"] = options.BWLimit
-		}
-		if options.TargetStorage != "" {
-			params["targetstorage"] = options.TargetStorage
-		}
-		if options.Delete {
-			params["delete"] = 1
-		}
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/lxc/%d/migrate", node, vmid), params)
-	if err != nil {
-		return nil, err
-	}
-
-	var result struct {
-		Data *Task
-	}
-	_, err = s.client.Do(req, &result)
-	if err != nil {
-		return nil, err
-	}
-
-	return result.Data, nil
+	return s.client.migrate(context.Background(), node, "lxc", vmid, target, options)
 }
 
 // Clone clones an LXC container
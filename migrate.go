@@ -0,0 +1,308 @@
+package pve
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// migrateParams builds the parameters of a migrate call for a guest type
+func migrateParams(vmType, target string, options *MigrateOptions) map[string]any {
+	params := map[string]any{
+		"target": target,
+	}
+
+	if options == nil {
+		return params
+	}
+
+	if options.Online {
+		params["online"] = 1
+	}
+	if options.BWLimit > 0 {
+		params["bwlimit"] = options.BWLimit
+	}
+
+	storageKey := "targetstorage"
+	if vmType == "lxc" {
+		storageKey = "target-storage"
+	}
+	if storage := targetMapping(options.TargetStorage, options.TargetStorages); storage != "" {
+		params[storageKey] = storage
+	}
+
+	switch vmType {
+	case "qemu":
+		if options.Force {
+			params["force"] = 1
+		}
+		if options.MigrationNetwork != "" {
+			params["migration_network"] = options.MigrationNetwork
+		}
+		if options.MigrationType != "" {
+			params["migration_type"] = options.MigrationType
+		}
+		if options.WithLocalDisks {
+			params["with-local-disks"] = 1
+		}
+		if options.WithConntrackState {
+			params["with-conntrack-state"] = 1
+		}
+	case "lxc":
+		if options.Restart {
+			params["restart"] = 1
+		}
+		if options.Timeout > 0 {
+			params["timeout"] = options.Timeout
+		}
+	}
+
+	return params
+}
+
+// migrate starts the migration of a guest to another node of the cluster
+func (c *Client) migrate(ctx context.Context, node, vmType string, vmid int, target string, options *MigrateOptions) (*Task, error) {
+	params := migrateParams(vmType, target, options)
+
+	req, err := c.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/migrate", node, vmType, vmid), params, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *Task
+	}
+	_, err = c.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// targetMapping builds a storage or bridge mapping of the form
+// source:target[,source:target...][,default]
+func targetMapping(defaultID string, mapping map[string]string) string {
+	entries := make([]string, 0, len(mapping)+1)
+	for source, target := range mapping {
+		entries = append(entries, source+":"+target)
+	}
+	sort.Strings(entries)
+
+	if defaultID != "" {
+		entries = append(entries, defaultID)
+	}
+
+	return strings.Join(entries, ",")
+}
+
+// MigratePreconditions retrieves the migration preconditions of a QEMU VM.
+// If target is set, storage availability is checked for that node only.
+func (s *QEMUService) MigratePreconditions(ctx context.Context, node string, vmid int, target string) (*MigratePreconditions, error) {
+	params := map[string]string{}
+	if target != "" {
+		params["target"] = target
+	}
+
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/migrate", node, vmid), params, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *MigratePreconditions
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// MigratePreconditions retrieves the migration preconditions of an LXC container.
+// If target is set, storage availability is checked for that node only.
+func (s *LXCService) MigratePreconditions(ctx context.Context, node string, vmid int, target string) (*MigratePreconditions, error) {
+	params := map[string]string{}
+	if target != "" {
+		params["target"] = target
+	}
+
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/lxc/%d/migrate", node, vmid), params, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	// Containers report the same information with hyphenated keys
+	var result struct {
+		Data *struct {
+			Running              int                            `json:"running"`
+			AllowedNodes         []string                       `json:"allowed-nodes"`
+			NotAllowedNodes      map[string]*MigrateNodeBlocker `json:"not-allowed-nodes"`
+			DependentHAResources []string                       `json:"dependent-ha-resources"`
+		} `json:"data"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Data == nil {
+		return nil, nil
+	}
+
+	return &MigratePreconditions{
+		Running:              result.Data.Running,
+		AllowedNodes:         result.Data.AllowedNodes,
+		NotAllowedNodes:      result.Data.NotAllowedNodes,
+		DependentHAResources: result.Data.DependentHAResources,
+	}, nil
+}
+
+// Blockers returns the reasons that prevent a migration to target. Local disks
+// are not reported since they can be moved with MigrateOptions.WithLocalDisks.
+func (p *MigratePreconditions) Blockers(target string) []string {
+	var reasons []string
+
+	if blocker, ok := p.NotAllowedNodes[target]; ok && blocker != nil {
+		for _, storage := range blocker.UnavailableStorages {
+			reasons = append(reasons, fmt.Sprintf("storage %s is not available on %s", storage, target))
+		}
+		for _, resource := range blocker.UnavailableResources {
+			reasons = append(reasons, fmt.Sprintf("mapped resource %s is not available on %s", resource, target))
+		}
+		for _, ha := range blocker.BlockingHAResources {
+			reasons = append(reasons, fmt.Sprintf("HA resource %s blocks migration (%s)", ha.SID, ha.Cause))
+		}
+	} else if p.AllowedNodes != nil && !slices.Contains(p.AllowedNodes, target) {
+		reasons = append(reasons, fmt.Sprintf("node %s is not an allowed target", target))
+	}
+
+	for _, resource := range p.LocalResources {
+		reasons = append(reasons, fmt.Sprintf("local resource %s", resource))
+	}
+
+	return reasons
+}
+
+// RemoteEndpoint describes the API endpoint of a remote cluster
+type RemoteEndpoint struct {
+	Host        string // Address of a node in the remote cluster
+	Port        int    // API port (default 8006)
+	TokenID     string // API token ID, e.g. root@pam!migrate
+	TokenSecret string // API token secret
+	Fingerprint string // Certificate fingerprint, required unless the certificate is trusted
+}
+
+// String returns the endpoint in the target-endpoint property string format
+func (e *RemoteEndpoint) String() string {
+	parts := []string{
+		fmt.Sprintf("apitoken=PVEAPIToken=%s=%s", e.TokenID, e.TokenSecret),
+		"host=" + e.Host,
+	}
+	if e.Fingerprint != "" {
+		parts = append(parts, "fingerprint="+e.Fingerprint)
+	}
+	if e.Port > 0 {
+		parts = append(parts, fmt.Sprintf("port=%d", e.Port))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// remoteMigrateParams builds the parameters of a remote_migrate call for a guest type.
+// Storage and bridge IDs are kept unless a mapping is given.
+func remoteMigrateParams(vmType string, endpoint *RemoteEndpoint, options *MigrateOptions) (map[string]any, error) {
+	if endpoint == nil || endpoint.Host == "" || endpoint.TokenID == "" || endpoint.TokenSecret == "" {
+		return nil, fmt.Errorf("remote migration requires an endpoint host and API token")
+	}
+	if options == nil {
+		options = &MigrateOptions{}
+	}
+
+	params := map[string]any{
+		"target-endpoint": endpoint.String(),
+		"target-storage":  "1",
+		"target-bridge":   "1",
+	}
+
+	if storage := targetMapping(options.TargetStorage, options.TargetStorages); storage != "" {
+		params["target-storage"] = storage
+	}
+	if bridge := targetMapping(options.TargetBridge, options.TargetBridges); bridge != "" {
+		params["target-bridge"] = bridge
+	}
+	if options.TargetVMID > 0 {
+		params["target-vmid"] = options.TargetVMID
+	}
+	if options.Online {
+		params["online"] = 1
+	}
+	if options.BWLimit > 0 {
+		params["bwlimit"] = options.BWLimit
+	}
+	if options.Delete {
+		params["delete"] = 1
+	}
+
+	if vmType == "lxc" {
+		if options.Restart {
+			params["restart"] = 1
+		}
+		if options.Timeout > 0 {
+			params["timeout"] = options.Timeout
+		}
+	}
+
+	return params, nil
+}
+
+// RemoteMigrate migrates a QEMU VM to another cluster
+func (s *QEMUService) RemoteMigrate(ctx context.Context, node string, vmid int, endpoint *RemoteEndpoint, options *MigrateOptions) (*Task, error) {
+	return s.client.remoteMigrate(ctx, node, "qemu", vmid, endpoint, options)
+}
+
+// RemoteMigrate migrates an LXC container to another cluster
+func (s *LXCService) RemoteMigrate(ctx context.Context, node string, vmid int, endpoint *RemoteEndpoint, options *MigrateOptions) (*Task, error) {
+	return s.client.remoteMigrate(ctx, node, "lxc", vmid, endpoint, options)
+}
+
+// RemoteMigrate migrates a VM or container to another cluster
+func (s *VMsService) RemoteMigrate(ctx context.Context, vmid int, endpoint *RemoteEndpoint, options *MigrateOptions) (*Task, error) {
+	var task *Task
+	err := s.withVM(ctx, vmid, func(vm *VM) error {
+		var err error
+		task, err = s.client.remoteMigrate(ctx, vm.Node, vm.Type, vmid, endpoint, options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// remoteMigrate starts a remote migration of a QEMU VM or LXC container
+func (c *Client) remoteMigrate(ctx context.Context, node, vmType string, vmid int, endpoint *RemoteEndpoint, options *MigrateOptions) (*Task, error) {
+	params, err := remoteMigrateParams(vmType, endpoint, options)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/remote_migrate", node, vmType, vmid), params, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *Task
+	}
+	_, err = c.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
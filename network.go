@@ -0,0 +1,350 @@
+package pve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Node network interface types
+const (
+	InterfaceEth        = "eth"
+	InterfaceBridge     = "bridge"
+	InterfaceBond       = "bond"
+	InterfaceVLAN       = "vlan"
+	InterfaceAlias      = "alias"
+	InterfaceOVSBridge  = "OVSBridge"
+	InterfaceOVSBond    = "OVSBond"
+	InterfaceOVSPort    = "OVSPort"
+	InterfaceOVSIntPort = "OVSIntPort"
+
+	// List filters only
+	InterfaceAnyBridge      = "any_bridge"
+	InterfaceAnyLocalBridge = "any_local_bridge"
+)
+
+// Bond modes
+const (
+	BondBalanceRR      = "balance-rr"
+	BondActiveBackup   = "active-backup"
+	BondBalanceXOR     = "balance-xor"
+	BondBroadcast      = "broadcast"
+	Bond8023AD         = "802.3ad"
+	BondBalanceTLB     = "balance-tlb"
+	BondBalanceALB     = "balance-alb"
+	BondBalanceSLB     = "balance-slb"      // OVS
+	BondLACPBalanceSLB = "lacp-balance-slb" // OVS
+	BondLACPBalanceTCP = "lacp-balance-tcp" // OVS
+)
+
+// NodeInterface represents a network interface in the configuration of a node.
+// Changes made through the API are pending until the configuration is applied.
+type NodeInterface struct {
+	Iface     string   `json:"iface"`
+	Type      string   `json:"type"`
+	Method    string   `json:"method"`  // static, manual, dhcp, ...
+	Method6   string   `json:"method6"` // IPv6 method
+	Active    int      `json:"active"`
+	Autostart int      `json:"autostart"`
+	Exists    int      `json:"exists"` // 1 if the device physically exists
+	Priority  int      `json:"priority"`
+	Families  []string `json:"families"`
+	Address   string   `json:"address"`
+	Netmask   string   `json:"netmask"`
+	CIDR      string   `json:"cidr"`
+	Gateway   string   `json:"gateway"`
+	Address6  string   `json:"address6"`
+	Netmask6  int      `json:"netmask6"`
+	CIDR6     string   `json:"cidr6"`
+	Gateway6  string   `json:"gateway6"`
+	MTU       int      `json:"mtu"`
+	Comments  string   `json:"comments"`
+	Comments6 string   `json:"comments6"`
+	Options   []string `json:"options"`  // Additional IPv4 options
+	Options6  []string `json:"options6"` // Additional IPv6 options
+
+	BridgePorts     string `json:"bridge_ports"` // Space-separated
+	BridgeVLANAware int    `json:"bridge_vlan_aware"`
+	BridgeVIDs      string `json:"bridge_vids"`
+
+	Slaves             string `json:"slaves"` // Space-separated bond members
+	BondMode           string `json:"bond_mode"`
+	BondPrimary        string `json:"bond-primary"`
+	BondXmitHashPolicy string `json:"bond_xmit_hash_policy"`
+
+	VLANID        int    `json:"vlan-id"`
+	VLANRawDevice string `json:"vlan-raw-device"`
+	VLANProtocol  string `json:"vlan-protocol"`
+
+	OVSBridge  string `json:"ovs_bridge"`
+	OVSPorts   string `json:"ovs_ports"`
+	OVSBonds   string `json:"ovs_bonds"`
+	OVSOptions string `json:"ovs_options"`
+	OVSTag     int    `json:"ovs_tag"`
+}
+
+// UnmarshalJSON decodes an interface whose numeric settings may be strings
+func (i *NodeInterface) UnmarshalJSON(data []byte) error {
+	type plain NodeInterface
+	aux := struct {
+		*plain
+		Netmask6 json.RawMessage `json:"netmask6"`
+		MTU      json.RawMessage `json:"mtu"`
+		VLANID   json.RawMessage `json:"vlan-id"`
+		OVSTag   json.RawMessage `json:"ovs_tag"`
+	}{plain: (*plain)(i)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	for _, field := range []struct {
+		name string
+		raw  json.RawMessage
+		dst  *int
+	}{
+		{"netmask6", aux.Netmask6, &i.Netmask6},
+		{"mtu", aux.MTU, &i.MTU},
+		{"vlan-id", aux.VLANID, &i.VLANID},
+		{"ovs_tag", aux.OVSTag, &i.OVSTag},
+	} {
+		s := strings.Trim(string(field.raw), `"`)
+		if s == "" || s == "null" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", field.name, s, err)
+		}
+		*field.dst = n
+	}
+
+	return nil
+}
+
+// NodeInterfaceOptions specifies the settings of a node network interface.
+// Unset fields are not sent; use Delete to remove a setting on update.
+type NodeInterfaceOptions struct {
+	Type      string // Interface type (required)
+	Autostart bool   // Start the interface on boot
+	CIDR      string // IPv4 address with prefix, e.g. 192.168.1.10/24
+	Gateway   string
+	CIDR6     string // IPv6 address with prefix
+	Gateway6  string
+	MTU       int
+	Comments  string
+	Comments6 string
+
+	BridgePorts     string // Space-separated ports of a bridge, e.g. "eno1 eno2"
+	BridgeVLANAware bool
+	BridgeVIDs      string // Allowed VLANs of a VLAN-aware bridge, e.g. "2 4 100-200"
+
+	Slaves             string // Space-separated members of a bond
+	BondMode           string
+	BondPrimary        string // Primary member for active-backup bonds
+	BondXmitHashPolicy string // layer2, layer2+3 or layer3+4
+
+	VLANID        int    // VLAN tag of a custom named VLAN interface
+	VLANRawDevice string // Parent device of a custom named VLAN interface
+
+	OVSBridge  string // OVS bridge of an OVS port
+	OVSPorts   string // Space-separated ports of an OVS bridge
+	OVSBonds   string // Space-separated members of an OVS bond
+	OVSOptions string
+	OVSTag     int
+
+	Delete []string // Settings to remove (update only)
+}
+
+// params converts the options to API parameters
+func (o *NodeInterfaceOptions) params() map[string]any {
+	params := map[string]any{
+		"type": o.Type,
+	}
+
+	set := func(key, value string) {
+		if value != "" {
+			params[key] = value
+		}
+	}
+	setInt := func(key string, value int) {
+		if value > 0 {
+			params[key] = value
+		}
+	}
+
+	if o.Autostart {
+		params["autostart"] = true
+	}
+	set("cidr", o.CIDR)
+	set("gateway", o.Gateway)
+	set("cidr6", o.CIDR6)
+	set("gateway6", o.Gateway6)
+	setInt("mtu", o.MTU)
+	set("comments", o.Comments)
+	set("comments6", o.Comments6)
+
+	set("bridge_ports", o.BridgePorts)
+	if o.BridgeVLANAware {
+		params["bridge_vlan_aware"] = true
+	}
+	set("bridge_vids", o.BridgeVIDs)
+
+	set("slaves", o.Slaves)
+	set("bond_mode", o.BondMode)
+	set("bond-primary", o.BondPrimary)
+	set("bond_xmit_hash_policy", o.BondXmitHashPolicy)
+
+	setInt("vlan-id", o.VLANID)
+	set("vlan-raw-device", o.VLANRawDevice)
+
+	set("ovs_bridge", o.OVSBridge)
+	set("ovs_ports", o.OVSPorts)
+	set("ovs_bonds", o.OVSBonds)
+	set("ovs_options", o.OVSOptions)
+	setInt("ovs_tag", o.OVSTag)
+
+	set("delete", strings.Join(o.Delete, ","))
+
+	return params
+}
+
+// ListInterfaces retrieves the network interfaces of a node. ifaceType
+// filters by type (e.g. InterfaceBridge or InterfaceAnyBridge), empty lists all.
+func (s *NodesService) ListInterfaces(ctx context.Context, name, ifaceType string) ([]*NodeInterface, error) {
+	params := map[string]string{}
+	if ifaceType != "" {
+		params["type"] = ifaceType
+	}
+
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/network", name), params, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []*NodeInterface
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// GetInterface retrieves a network interface of a node, including pending changes
+func (s *NodesService) GetInterface(ctx context.Context, name, iface string) (*NodeInterface, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/network/%s", name, iface), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *NodeInterface
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Data != nil && result.Data.Iface == "" {
+		result.Data.Iface = iface
+	}
+
+	return result.Data, nil
+}
+
+// CreateInterface adds a network interface to the pending configuration of a node
+func (s *NodesService) CreateInterface(ctx context.Context, name, iface string, options *NodeInterfaceOptions) error {
+	if options == nil || options.Type == "" {
+		return errors.New("interface type is required")
+	}
+
+	params := options.params()
+	params["iface"] = iface
+
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/network", name), params, WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
+
+// UpdateInterface changes a network interface in the pending configuration of a node
+func (s *NodesService) UpdateInterface(ctx context.Context, name, iface string, options *NodeInterfaceOptions) error {
+	if options == nil || options.Type == "" {
+		return errors.New("interface type is required")
+	}
+
+	req, err := s.client.NewRequest("PUT", fmt.Sprintf("nodes/%s/network/%s", name, iface), options.params(), WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
+
+// DeleteInterface removes a network interface from the pending configuration of a node
+func (s *NodesService) DeleteInterface(ctx context.Context, name, iface string) error {
+	req, err := s.client.NewRequest("DELETE", fmt.Sprintf("nodes/%s/network/%s", name, iface), nil, WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
+
+// PendingNetworkChanges returns the diff between the active and the pending
+// network configuration of a node. It is empty if nothing is pending.
+func (s *NodesService) PendingNetworkChanges(ctx context.Context, name string) (string, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/network", name), nil, WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Changes string `json:"changes"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return "", err
+	}
+
+	return result.Changes, nil
+}
+
+// ApplyNetwork applies the pending network configuration of a node (ifreload)
+func (s *NodesService) ApplyNetwork(ctx context.Context, name string) (*Task, error) {
+	req, err := s.client.NewRequest("PUT", fmt.Sprintf("nodes/%s/network", name), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *Task
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// RevertNetwork discards the pending network configuration of a node
+func (s *NodesService) RevertNetwork(ctx context.Context, name string) error {
+	req, err := s.client.NewRequest("DELETE", fmt.Sprintf("nodes/%s/network", name), nil, WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
@@ -0,0 +1,362 @@
+package pve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// hostsUpdateRetries is the number of attempts for a hosts update whose digest went stale
+const hostsUpdateRetries = 3
+
+// NodeDNS holds the DNS settings of a node
+type NodeDNS struct {
+	Search string `json:"search"` // Search domain (required when writing)
+	DNS1   string `json:"dns1"`
+	DNS2   string `json:"dns2"`
+	DNS3   string `json:"dns3"`
+}
+
+// NodeHosts holds the content of /etc/hosts of a node
+type NodeHosts struct {
+	Data   string `json:"data"`
+	Digest string `json:"digest"`
+}
+
+// NodeTime holds the time settings of a node
+type NodeTime struct {
+	Timezone  string `json:"timezone"`
+	Time      int64  `json:"time"`      // Seconds since the epoch (UTC)
+	LocalTime int64  `json:"localtime"` // Seconds since the epoch (local time)
+}
+
+// GetDNS retrieves the DNS settings of a node
+func (s *NodesService) GetDNS(ctx context.Context, name string) (*NodeDNS, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/dns", name), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *NodeDNS
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// SetDNS writes the DNS settings of a node. Name servers left empty are removed.
+func (s *NodesService) SetDNS(ctx context.Context, name string, dns *NodeDNS) error {
+	if dns == nil || dns.Search == "" {
+		return errors.New("search domain is required")
+	}
+
+	params := map[string]string{
+		"search": dns.Search,
+	}
+	for key, value := range map[string]string{"dns1": dns.DNS1, "dns2": dns.DNS2, "dns3": dns.DNS3} {
+		if value != "" {
+			params[key] = value
+		}
+	}
+
+	req, err := s.client.NewRequest("PUT", fmt.Sprintf("nodes/%s/dns", name), params, WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
+
+// GetHosts retrieves /etc/hosts of a node
+func (s *NodesService) GetHosts(ctx context.Context, name string) (*NodeHosts, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/hosts", name), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *NodeHosts
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// SetHosts writes /etc/hosts of a node. If digest is set, the write fails when
+// the file was changed since it was read.
+func (s *NodesService) SetHosts(ctx context.Context, name, data, digest string) error {
+	params := map[string]string{
+		"data": data,
+	}
+	if digest != "" {
+		params["digest"] = digest
+	}
+
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/hosts", name), params, WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
+
+// UpdateHosts applies update to /etc/hosts of a node. The digest of the read
+// content is sent along so that concurrent changes are detected, in which case
+// the update is retried with the fresh content. It reports whether the file changed.
+func (s *NodesService) UpdateHosts(ctx context.Context, name string, update func(string) string) (bool, error) {
+	var lastErr error
+	for attempt := 0; attempt < hostsUpdateRetries; attempt++ {
+		hosts, err := s.GetHosts(ctx, name)
+		if err != nil {
+			return false, err
+		}
+
+		data := update(hosts.Data)
+		if data == hosts.Data {
+			return false, nil
+		}
+
+		// A stale digest is reported as "detected modified configuration"
+		err = s.SetHosts(ctx, name, data, hosts.Digest)
+		if err == nil {
+			return true, nil
+		}
+		if !strings.Contains(err.Error(), "modified configuration") {
+			return false, err
+		}
+		lastErr = err
+	}
+
+	return false, lastErr
+}
+
+// SetHostsEntry makes ip resolve to hostnames in /etc/hosts of a node,
+// replacing an existing line for ip. It reports whether the file changed.
+func (s *NodesService) SetHostsEntry(ctx context.Context, name, ip string, hostnames ...string) (bool, error) {
+	return s.UpdateHosts(ctx, name, func(data string) string {
+		return setHostsEntry(data, ip, hostnames)
+	})
+}
+
+// setHostsEntry replaces the first line for ip with ip and hostnames, or
+// appends one, and drops further lines for ip. A trailing comment of the
+// replaced line is kept.
+func setHostsEntry(data, ip string, hostnames []string) string {
+	entry := ip + " " + strings.Join(hostnames, " ")
+
+	lines := strings.Split(strings.TrimSuffix(data, "\n"), "\n")
+	out := make([]string, 0, len(lines)+1)
+	found := false
+	for _, line := range lines {
+		fields, comment := hostsLine(line)
+		if len(fields) == 0 || fields[0] != ip {
+			out = append(out, line)
+			continue
+		}
+		if !found {
+			if comment != "" {
+				out = append(out, entry+" "+comment)
+			} else {
+				out = append(out, entry)
+			}
+			found = true
+		}
+	}
+	if !found {
+		if len(out) == 1 && out[0] == "" {
+			out = out[:0]
+		}
+		out = append(out, entry)
+	}
+
+	return strings.Join(out, "\n") + "\n"
+}
+
+// hostsLine splits a hosts line into its fields and its comment (including #)
+func hostsLine(line string) ([]string, string) {
+	content, comment, found := strings.Cut(line, "#")
+	if found {
+		comment = "#" + comment
+	}
+
+	return strings.Fields(content), strings.TrimSpace(comment)
+}
+
+// GetTime retrieves the time and time zone of a node
+func (s *NodesService) GetTime(ctx context.Context, name string) (*NodeTime, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/time", name), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *NodeTime
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// SetTimezone sets the time zone of a node, e.g. Europe/Vienna
+func (s *NodesService) SetTimezone(ctx context.Context, name, timezone string) error {
+	params := map[string]string{
+		"timezone": timezone,
+	}
+
+	req, err := s.client.NewRequest("PUT", fmt.Sprintf("nodes/%s/time", name), params, WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
+
+// NodeBaseline is the desired system configuration of all nodes. Unset parts
+// are left alone.
+type NodeBaseline struct {
+	DNS      *NodeDNS            // DNS settings, written as a whole
+	Timezone string              // Time zone
+	Hosts    map[string][]string // Entries ensured in /etc/hosts, IP to hostnames
+	DryRun   bool                // Only report the changes
+}
+
+// NodeConvergence is the outcome of converging a single node
+type NodeConvergence struct {
+	Node    string
+	Changed []string // Settings that were (or in a dry run would be) changed: dns, timezone, hosts
+	Skipped string   // Reason the node was not touched
+	Err     error
+}
+
+// Converge applies baseline to every node in List. Offline nodes are skipped
+// and settings that already match are not written. The returned error joins
+// the errors of all failed nodes.
+func (s *NodesService) Converge(ctx context.Context, baseline *NodeBaseline) ([]*NodeConvergence, error) {
+	nodes, err := s.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+	var (
+		results []*NodeConvergence
+		errs    []error
+	)
+	for _, node := range nodes {
+		result := &NodeConvergence{Node: node.Name}
+		results = append(results, result)
+
+		if node.Status != "online" {
+			result.Skipped = "node is " + node.Status
+			continue
+		}
+
+		if result.Err = s.converge(ctx, node.Name, baseline, result); result.Err != nil {
+			errs = append(errs, fmt.Errorf("node %s: %w", node.Name, result.Err))
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// converge applies baseline to a single node and records the changes in result
+func (s *NodesService) converge(ctx context.Context, name string, baseline *NodeBaseline, result *NodeConvergence) error {
+	if baseline.DNS != nil {
+		current, err := s.GetDNS(ctx, name)
+		if err != nil {
+			return fmt.Errorf("read dns: %w", err)
+		}
+		if current == nil || *current != *baseline.DNS {
+			result.Changed = append(result.Changed, "dns")
+			if !baseline.DryRun {
+				if err := s.SetDNS(ctx, name, baseline.DNS); err != nil {
+					return fmt.Errorf("write dns: %w", err)
+				}
+			}
+		}
+	}
+
+	if baseline.Timezone != "" {
+		current, err := s.GetTime(ctx, name)
+		if err != nil {
+			return fmt.Errorf("read time: %w", err)
+		}
+		if current == nil || current.Timezone != baseline.Timezone {
+			result.Changed = append(result.Changed, "timezone")
+			if !baseline.DryRun {
+				if err := s.SetTimezone(ctx, name, baseline.Timezone); err != nil {
+					return fmt.Errorf("write timezone: %w", err)
+				}
+			}
+		}
+	}
+
+	if len(baseline.Hosts) > 0 {
+		ips := make([]string, 0, len(baseline.Hosts))
+		for ip := range baseline.Hosts {
+			ips = append(ips, ip)
+		}
+		sort.Strings(ips)
+
+		update := func(data string) string {
+			for _, ip := range ips {
+				if !hasHostsEntry(data, ip, baseline.Hosts[ip]) {
+					data = setHostsEntry(data, ip, baseline.Hosts[ip])
+				}
+			}
+			return data
+		}
+
+		var changed bool
+		if baseline.DryRun {
+			hosts, err := s.GetHosts(ctx, name)
+			if err != nil {
+				return fmt.Errorf("read hosts: %w", err)
+			}
+			changed = update(hosts.Data) != hosts.Data
+		} else {
+			var err error
+			if changed, err = s.UpdateHosts(ctx, name, update); err != nil {
+				return fmt.Errorf("write hosts: %w", err)
+			}
+		}
+		if changed {
+			result.Changed = append(result.Changed, "hosts")
+		}
+	}
+
+	return nil
+}
+
+// hasHostsEntry reports whether the only line for ip lists exactly hostnames
+func hasHostsEntry(data, ip string, hostnames []string) bool {
+	matches := 0
+	equal := false
+	for _, line := range strings.Split(data, "\n") {
+		fields, _ := hostsLine(line)
+		if len(fields) == 0 || fields[0] != ip {
+			continue
+		}
+		matches++
+		equal = slices.Equal(fields[1:], hostnames)
+	}
+
+	return matches == 1 && equal
+}
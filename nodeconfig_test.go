@@ -0,0 +1,96 @@
+package pve
+
+import "testing"
+
+func TestSetHostsEntry(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		ip        string
+		hostnames []string
+		want      string
+	}{
+		{
+			name:      "empty file",
+			data:      "",
+			ip:        "10.0.0.1",
+			hostnames: []string{"pve1.example.com", "pve1"},
+			want:      "10.0.0.1 pve1.example.com pve1\n",
+		},
+		{
+			name:      "append",
+			data:      "127.0.0.1 localhost\n",
+			ip:        "10.0.0.1",
+			hostnames: []string{"pve1"},
+			want:      "127.0.0.1 localhost\n10.0.0.1 pve1\n",
+		},
+		{
+			name:      "replace in place",
+			data:      "127.0.0.1 localhost\n10.0.0.1 old\n::1 localhost\n",
+			ip:        "10.0.0.1",
+			hostnames: []string{"pve1"},
+			want:      "127.0.0.1 localhost\n10.0.0.1 pve1\n::1 localhost\n",
+		},
+		{
+			name:      "drop duplicates",
+			data:      "10.0.0.1 a\n10.0.0.2 b\n10.0.0.1 c\n",
+			ip:        "10.0.0.1",
+			hostnames: []string{"pve1"},
+			want:      "10.0.0.1 pve1\n10.0.0.2 b\n",
+		},
+		{
+			name:      "keep trailing comment",
+			data:      "10.0.0.1\told # managed by ansible\n",
+			ip:        "10.0.0.1",
+			hostnames: []string{"pve1"},
+			want:      "10.0.0.1 pve1 # managed by ansible\n",
+		},
+		{
+			name:      "ignore commented out line",
+			data:      "# 10.0.0.1 old\n",
+			ip:        "10.0.0.1",
+			hostnames: []string{"pve1"},
+			want:      "# 10.0.0.1 old\n10.0.0.1 pve1\n",
+		},
+		{
+			name:      "no trailing newline",
+			data:      "127.0.0.1 localhost",
+			ip:        "10.0.0.1",
+			hostnames: []string{"pve1"},
+			want:      "127.0.0.1 localhost\n10.0.0.1 pve1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setHostsEntry(tt.data, tt.ip, tt.hostnames); got != tt.want {
+				t.Errorf("setHostsEntry() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasHostsEntry(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		hostnames []string
+		want      bool
+	}{
+		{"match", "10.0.0.1 pve1.example.com pve1\n", []string{"pve1.example.com", "pve1"}, true},
+		{"match with comment", "10.0.0.1 pve1 # managed\n", []string{"pve1"}, true},
+		{"different order", "10.0.0.1 pve1 pve1.example.com\n", []string{"pve1.example.com", "pve1"}, false},
+		{"extra hostname", "10.0.0.1 pve1 extra\n", []string{"pve1"}, false},
+		{"duplicate lines", "10.0.0.1 pve1\n10.0.0.1 pve1\n", []string{"pve1"}, false},
+		{"commented out", "# 10.0.0.1 pve1\n", []string{"pve1"}, false},
+		{"missing", "127.0.0.1 localhost\n", []string{"pve1"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasHostsEntry(tt.data, "10.0.0.1", tt.hostnames); got != tt.want {
+				t.Errorf("hasHostsEntry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,12 @@
 package pve
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // NodesService handles node-related API operations
@@ -11,7 +16,12 @@ type NodesService struct {
 
 // List retrieves all nodes
 func (s *NodesService) List() ([]*Node, error) {
-	req, err := s.client.NewRequest("GET", "nodes", nil)
+	return s.list(context.Background())
+}
+
+// list retrieves all nodes within ctx
+func (s *NodesService) list(ctx context.Context) ([]*Node, error) {
+	req, err := s.client.NewRequest("GET", "nodes", nil, WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -45,31 +55,32 @@ func (s *NodesService) Get(name string) (*Node, error) {
 
 // GetDetailed retrieves detailed node information
 func (s *NodesService) GetDetailed(name string) (*NodeInfo, error) {
-	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s", name), nil)
+	status, err := s.GetStatus(name)
 	if err != nil {
 		return nil, err
 	}
 
-	var result struct {
-		Data *NodeInfo
-	}
-	_, err = s.client.Do(req, &result)
-	if err != nil {
-		return nil, err
-	}
-
-	return result.Data, nil
+	return &NodeInfo{
+		Node:       name,
+		CPUInfo:    status.CPUInfo,
+		Kversion:   status.Kversion,
+		PVEVersion: status.PVEVersion,
+		MaxCPU:     status.CPUInfo.CPUs,
+		MaxDisk:    status.RootFS.Total,
+		MaxMem:     status.Memory.Total,
+		Uptime:     status.Uptime,
+	}, nil
 }
 
 // GetStatus retrieves node status
-func (s *NodesService) GetStatus(name string) (map[string]any, error) {
+func (s *NodesService) GetStatus(name string) (*NodeStatus, error) {
 	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/status", name), nil)
 	if err != nil {
 		return nil, err
 	}
 
 	var result struct {
-		Data map[string]any
+		Data *NodeStatus
 	}
 	_, err = s.client.Do(req, &result)
 	if err != nil {
@@ -79,6 +90,26 @@ func (s *NodesService) GetStatus(name string) (map[string]any, error) {
 	return result.Data, nil
 }
 
+// UnmarshalJSON decodes load averages, which PVE returns as strings
+func (l *LoadAvg) UnmarshalJSON(data []byte) error {
+	var values []json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	*l = LoadAvg{}
+	for i := 0; i < len(values) && i < len(l); i++ {
+		raw := strings.Trim(string(values[i]), `"`)
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid load average %q: %w", raw, err)
+		}
+		l[i] = v
+	}
+
+	return nil
+}
+
 // GetVersion retrieves node version information
 func (s *NodesService) GetVersion(name string) (map[string]any, error) {
 	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/status/version", name), nil)
@@ -133,6 +164,12 @@ func (s *NodesService) CreateVNCShell(name string) (map[string]any, error) {
 	return result.Data, nil
 }
 
+// SpiceShell creates a SPICE shell for node access. Proxy overrides the SPICE
+// proxy the viewer connects through.
+func (s *NodesService) SpiceShell(ctx context.Context, name, proxy string) (*SpiceConfig, error) {
+	return s.client.spiceConfig(ctx, fmt.Sprintf("nodes/%s/spiceshell", name), proxy)
+}
+
 // GetSubscription retrieves node subscription information
 func (s *NodesService) GetSubscription(name string) (map[string]any, error) {
 	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/subscription", name), nil)
@@ -171,24 +208,11 @@ func (s *NodesService) GetSyslog(name string, lines int) ([]string, error) {
 	return result.Data, nil
 }
 
-// GetRRD retrieves node RRD (Round Robin Database) data
-func (s *NodesService) GetRRD(name, timeframe string) (map[string]any, error) {
-	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/rrddata", name), map[string]any{
-		"timeframe": timeframe,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	var result struct {
-		Data map[string]any
-	}
-	_, err = s.client.Do(req, &result)
-	if err != nil {
-		return nil, err
-	}
-
-	return result.Data, nil
+// GetRRD retrieves averaged node RRD (Round Robin Database) data.
+//
+// Deprecated: use RRDData.
+func (s *NodesService) GetRRD(name, timeframe string) ([]Sample, error) {
+	return s.RRDData(context.Background(), name, RRDTimeframe(timeframe), RRDAverage)
 }
 
 // GetTasks retrieves node tasks
@@ -209,76 +233,182 @@ func (s *NodesService) GetTasks(name string) ([]*Task, error) {
 	return result.Data, nil
 }
 
-// StartNode starts a node (used for start/stop of services, not shutdown)
+// Start starts all guests of a node that have onboot=1.
+//
+// Deprecated: use StartAll.
 func (s *NodesService) Start(name string) (*Task, error) {
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/status/start", name), nil)
+	return s.StartAll(context.Background(), name, nil)
+}
+
+// Stop shuts down all guests of a node.
+//
+// Deprecated: use StopAll.
+func (s *NodesService) Stop(name string) (*Task, error) {
+	return s.StopAll(context.Background(), name, nil)
+}
+
+// Shutdown shuts down a node without any safety checks. The endpoint starts
+// no task, the returned task is always nil.
+//
+// Deprecated: use ShutdownNode or SafeShutdown.
+func (s *NodesService) Shutdown(name string) (*Task, error) {
+	return nil, s.ShutdownNode(context.Background(), name)
+}
+
+// Reboot reboots a node without any safety checks. The endpoint starts no
+// task, the returned task is always nil.
+//
+// Deprecated: use RebootNode or SafeReboot.
+func (s *NodesService) Reboot(name string) (*Task, error) {
+	return nil, s.RebootNode(context.Background(), name)
+}
+
+// ShutdownNode shuts down a node without any safety checks
+func (s *NodesService) ShutdownNode(ctx context.Context, name string) error {
+	return s.powerCommand(ctx, name, "shutdown")
+}
+
+// RebootNode reboots a node without any safety checks
+func (s *NodesService) RebootNode(ctx context.Context, name string) error {
+	return s.powerCommand(ctx, name, "reboot")
+}
+
+// SafeShutdown shuts down a node unless guests are still running on it or the
+// cluster would lose quorum. Force skips these checks.
+func (s *NodesService) SafeShutdown(ctx context.Context, name string, force bool) error {
+	return s.safePowerCommand(ctx, name, "shutdown", force)
+}
+
+// SafeReboot reboots a node unless guests are still running on it or the
+// cluster would lose quorum while it is down. Force skips these checks.
+func (s *NodesService) SafeReboot(ctx context.Context, name string, force bool) error {
+	return s.safePowerCommand(ctx, name, "reboot", force)
+}
+
+// PowerBlockers returns the reasons that make powering off a node unsafe.
+// Each node is assumed to have one quorum vote.
+func (s *NodesService) PowerBlockers(ctx context.Context, name string) ([]string, error) {
+	var reasons []string
+
+	guests, err := s.client.Cluster.VMResources(ctx)
 	if err != nil {
 		return nil, err
 	}
-
-	var result struct {
-		Data *Task
+	for _, guest := range guests.OnNode(name).WithStatus("running") {
+		reasons = append(reasons, fmt.Sprintf("%s %d (%s) is running", guest.Type, guest.VMID, guest.Name))
 	}
-	_, err = s.client.Do(req, &result)
+
+	status, err := s.client.Cluster.status(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return result.Data, nil
-}
+	var cluster *ClusterStatus
+	online, nodeOnline := 0, false
+	for _, entry := range status {
+		switch entry.Type {
+		case "cluster":
+			cluster = entry
+		case "node":
+			if entry.Online == 1 {
+				online++
+				if entry.Name == name {
+					nodeOnline = true
+				}
+			}
+		}
+	}
 
-// StopNode stops a node
-func (s *NodesService) Stop(name string) (*Task, error) {
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/status/stop", name), nil)
-	if err != nil {
-		return nil, err
+	// A standalone node has no quorum to lose
+	if cluster != nil && nodeOnline && (online-1)*2 <= cluster.Nodes {
+		reasons = append(reasons, fmt.Sprintf("cluster would lose quorum with %d of %d nodes online", online-1, cluster.Nodes))
 	}
 
-	var result struct {
-		Data *Task
+	return reasons, nil
+}
+
+// safePowerCommand runs a power command after checking PowerBlockers
+func (s *NodesService) safePowerCommand(ctx context.Context, name, command string, force bool) error {
+	if !force {
+		reasons, err := s.PowerBlockers(ctx, name)
+		if err != nil {
+			return err
+		}
+		if len(reasons) > 0 {
+			return fmt.Errorf("refusing to %s node %s: %s", command, name, strings.Join(reasons, "; "))
+		}
 	}
-	_, err = s.client.Do(req, &result)
+
+	return s.powerCommand(ctx, name, command)
+}
+
+// powerCommand posts a reboot or shutdown command to a node
+func (s *NodesService) powerCommand(ctx context.Context, name, command string) error {
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/status", name), map[string]string{
+		"command": command,
+	}, WithContext(ctx))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return result.Data, nil
+	_, err = s.client.Do(req, nil)
+	return err
 }
 
-// ShutdownNode shuts down a node
-func (s *NodesService) Shutdown(name string) (*Task, error) {
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/status/shutdown", name), nil)
+// WakeOnLAN sends a wake-on-LAN packet to a node and returns the MAC address
+// it was sent to. The request has to go to another online node of the cluster.
+func (s *NodesService) WakeOnLAN(ctx context.Context, name string) (string, error) {
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/wakeonlan", name), nil, WithContext(ctx))
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	var result struct {
-		Data *Task
+		Data string
 	}
 	_, err = s.client.Do(req, &result)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	return result.Data, nil
 }
 
-// RebootNode reboots a node
-func (s *NodesService) Reboot(name string) (*Task, error) {
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/status/reboot", name), nil)
-	if err != nil {
-		return nil, err
-	}
+// WaitOnline polls the cluster status until a node is reported online or ctx is done
+func (s *NodesService) WaitOnline(ctx context.Context, name string) error {
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Status requests fail while the cluster is busy re-forming, retry them
+		status, err := s.client.Cluster.status(ctx)
+		if err == nil {
+			for _, entry := range status {
+				if entry.Type == "node" && entry.Name == name && entry.Online == 1 {
+					return nil
+				}
+			}
+		}
 
-	var result struct {
-		Data *Task
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("%w: %w", ctx.Err(), err)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
 	}
-	_, err = s.client.Do(req, &result)
-	if err != nil {
-		return nil, err
+}
+
+// Wake sends a wake-on-LAN packet to a node and waits until it is online.
+// Use a ctx with a deadline to bound the boot time.
+func (s *NodesService) Wake(ctx context.Context, name string) error {
+	if _, err := s.WakeOnLAN(ctx, name); err != nil {
+		return err
 	}
 
-	return result.Data, nil
+	return s.WaitOnline(ctx, name)
 }
 
 // GetStorage retrieves storage information for a node
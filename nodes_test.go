@@ -0,0 +1,47 @@
+package pve
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLoadAvgUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    LoadAvg
+		wantErr bool
+	}{
+		{"strings", `["0.35","0.42","0.51"]`, LoadAvg{0.35, 0.42, 0.51}, false},
+		{"numbers", `[0.35,0.42,0.51]`, LoadAvg{0.35, 0.42, 0.51}, false},
+		{"mixed", `["1.00",2,"3.5"]`, LoadAvg{1, 2, 3.5}, false},
+		{"short", `["0.1"]`, LoadAvg{0.1, 0, 0}, false},
+		{"extra ignored", `["1","2","3","4"]`, LoadAvg{1, 2, 3}, false},
+		{"invalid", `["high","0","0"]`, LoadAvg{}, true},
+		{"not an array", `"0.35"`, LoadAvg{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got LoadAvg
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Unmarshal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodeStatusLoadAvg(t *testing.T) {
+	var status NodeStatus
+	data := `{"uptime":3600,"loadavg":["0.35","0.42","0.51"],"cpu":0.01}`
+	if err := json.Unmarshal([]byte(data), &status); err != nil {
+		t.Fatal(err)
+	}
+	if want := (LoadAvg{0.35, 0.42, 0.51}); status.LoadAvg != want {
+		t.Errorf("LoadAvg = %v, want %v", status.LoadAvg, want)
+	}
+}
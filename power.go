@@ -0,0 +1,329 @@
+package pve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultShutdownTimeout is the time a guest gets to shut down cleanly
+	defaultShutdownTimeout = 3 * time.Minute
+	// statusPollInterval is the interval between guest status checks
+	statusPollInterval = 2 * time.Second
+)
+
+// PowerOffPath describes how a guest was powered off
+type PowerOffPath string
+
+const (
+	PowerOffNone          PowerOffPath = "none"           // Guest was already stopped
+	PowerOffShutdown      PowerOffPath = "shutdown"       // Guest shut down after an ACPI/container shutdown request
+	PowerOffAgentShutdown PowerOffPath = "agent-shutdown" // Guest shut down after a guest agent shutdown request
+	PowerOffStop          PowerOffPath = "stop"           // Guest was stopped forcefully after the shutdown timed out
+)
+
+// PowerOffOptions specifies options for a graceful power off with escalation
+type PowerOffOptions struct {
+	Timeout          time.Duration // Time to wait for a clean shutdown (default 3m)
+	UseAgent         bool          // Shut down via the QEMU guest agent instead of ACPI (QEMU only)
+	NoEscalate       bool          // Do not stop the guest if the shutdown times out
+	OverruleShutdown bool          // Abort active shutdown tasks before stopping
+	SkipLock         bool          // Ignore locks when stopping (root only)
+}
+
+// PowerOffResult reports which path a power off took
+type PowerOffResult struct {
+	Path         PowerOffPath
+	ShutdownTask *Task         // Shutdown task, nil for agent shutdown
+	StopTask     *Task         // Stop task if the shutdown was escalated
+	ShutdownErr  error         // Error of the shutdown attempt that caused escalation
+	Duration     time.Duration // Total time until the guest was stopped
+}
+
+// PowerOff shuts down a QEMU VM and stops it if it does not power off in time
+func (s *QEMUService) PowerOff(ctx context.Context, node string, vmid int, opts *PowerOffOptions) (*PowerOffResult, error) {
+	return s.client.powerOff(ctx, node, "qemu", vmid, opts)
+}
+
+// PowerOff shuts down an LXC container and stops it if it does not power off in time
+func (s *LXCService) PowerOff(ctx context.Context, node string, vmid int, opts *PowerOffOptions) (*PowerOffResult, error) {
+	return s.client.powerOff(ctx, node, "lxc", vmid, opts)
+}
+
+// PowerOff shuts down a VM or container and stops it if it does not power off in time
+func (s *VMsService) PowerOff(ctx context.Context, vmid int, opts *PowerOffOptions) (*PowerOffResult, error) {
+	var result *PowerOffResult
+	err := s.withVM(ctx, vmid, func(vm *VM) error {
+		var err error
+		result, err = s.client.powerOff(ctx, vm.Node, vm.Type, vmid, opts)
+		return err
+	})
+
+	return result, err
+}
+
+// powerOff implements the shutdown-then-stop workflow for QEMU and LXC guests
+func (c *Client) powerOff(ctx context.Context, node, vmType string, vmid int, opts *PowerOffOptions) (*PowerOffResult, error) {
+	if opts == nil {
+		opts = &PowerOffOptions{}
+	}
+	if opts.UseAgent && vmType != "qemu" {
+		return nil, fmt.Errorf("agent shutdown is only supported for QEMU VMs")
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	start := time.Now()
+	result := &PowerOffResult{Path: PowerOffNone}
+
+	status, err := c.guestStatus(ctx, node, vmType, vmid)
+	if err != nil {
+		return nil, err
+	}
+	if status.Status == "stopped" {
+		return result, nil
+	}
+
+	// Request a clean shutdown
+	if opts.UseAgent {
+		result.Path = PowerOffAgentShutdown
+		result.ShutdownErr = c.QEMU.AgentShutdown(ctx, node, vmid)
+	} else {
+		result.Path = PowerOffShutdown
+		result.ShutdownTask, result.ShutdownErr = c.guestAction(ctx, node, vmType, vmid, "shutdown", map[string]any{
+			"timeout": int(timeout.Seconds()),
+		})
+	}
+
+	// Wait for the guest to stop
+	if result.ShutdownErr == nil {
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		_, result.ShutdownErr = c.waitGuestStatus(waitCtx, node, vmType, vmid, func(st *VMStatus) bool {
+			return st.Status == "stopped"
+		})
+		cancel()
+		if result.ShutdownErr == nil {
+			result.Duration = time.Since(start)
+			return result, nil
+		}
+	}
+
+	// Give up if the caller cancelled or escalation is disabled
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	if opts.NoEscalate {
+		return result, fmt.Errorf("shutdown of %s %d failed: %w", vmType, vmid, result.ShutdownErr)
+	}
+
+	// Escalate to stop
+	params := map[string]any{}
+	if opts.OverruleShutdown {
+		params["overrule-shutdown"] = 1
+	}
+	if opts.SkipLock {
+		params["skiplock"] = 1
+	}
+
+	result.Path = PowerOffStop
+	result.StopTask, err = c.guestAction(ctx, node, vmType, vmid, "stop", params)
+	if err != nil {
+		return result, err
+	}
+	if result.StopTask, err = c.Tasks.Wait(ctx, result.StopTask); err != nil {
+		return result, err
+	}
+
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// guestStatus retrieves the current status of a QEMU VM or LXC container
+func (c *Client) guestStatus(ctx context.Context, node, vmType string, vmid int) (*VMStatus, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("nodes/%s/%s/%d/status/current", node, vmType, vmid), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *VMStatus
+	}
+	_, err = c.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Data == nil {
+		return nil, fmt.Errorf("%s %d not found on node %s", vmType, vmid, node)
+	}
+
+	return result.Data, nil
+}
+
+// guestAction posts a status command (start, stop, shutdown, ...) to a guest
+func (c *Client) guestAction(ctx context.Context, node, vmType string, vmid int, action string, params map[string]any) (*Task, error) {
+	req, err := c.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/status/%s", node, vmType, vmid, action), params, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *Task
+	}
+	_, err = c.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Data == nil {
+		return nil, errors.New("no task returned for " + action)
+	}
+
+	return result.Data, nil
+}
+
+// waitGuestStatus polls the guest status until cond returns true or ctx is done
+func (c *Client) waitGuestStatus(ctx context.Context, node, vmType string, vmid int, cond func(*VMStatus) bool) (*VMStatus, error) {
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+
+	for {
+		status, err := c.guestStatus(ctx, node, vmType, vmid)
+		if err != nil {
+			return nil, err
+		}
+		if cond(status) {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// PowerState represents the power state of a guest
+type PowerState string
+
+const (
+	PowerStateRunning   PowerState = "running"
+	PowerStateStopped   PowerState = "stopped"
+	PowerStatePaused    PowerState = "paused"    // QEMU VM paused via suspend
+	PowerStateSuspended PowerState = "suspended" // Guest suspended itself (S3) or was hibernated to disk
+	PowerStatePrelaunch PowerState = "prelaunch" // QEMU process started but the VM has not run yet
+)
+
+// guestPowerState derives the power state from a guest status
+func guestPowerState(status *VMStatus) PowerState {
+	if status.Status == "stopped" {
+		if status.Lock == "suspended" {
+			return PowerStateSuspended
+		}
+		return PowerStateStopped
+	}
+
+	switch status.QMPStatus {
+	case "paused":
+		return PowerStatePaused
+	case "suspended":
+		return PowerStateSuspended
+	case "prelaunch":
+		return PowerStatePrelaunch
+	default:
+		return PowerStateRunning
+	}
+}
+
+// EnsureState converges a VM or container to the desired power state (running,
+// stopped or paused). It issues the minimal transition, waits for its task and
+// for the final status, and does nothing if the guest is already in that state.
+func (s *VMsService) EnsureState(ctx context.Context, vmid int, desired PowerState) (*VMStatus, error) {
+	var status *VMStatus
+	err := s.withVM(ctx, vmid, func(vm *VM) error {
+		var err error
+		status, err = s.client.ensureState(ctx, vm.Node, vm.Type, vmid, desired)
+		return err
+	})
+
+	return status, err
+}
+
+// ensureState implements EnsureState for a guest on a known node
+func (c *Client) ensureState(ctx context.Context, node, vmType string, vmid int, desired PowerState) (*VMStatus, error) {
+	if desired == PowerStatePaused && vmType != "qemu" {
+		return nil, fmt.Errorf("paused state is only supported for QEMU VMs")
+	}
+
+	status, err := c.guestStatus(ctx, node, vmType, vmid)
+	if err != nil {
+		return nil, err
+	}
+
+	current := guestPowerState(status)
+	if current == desired {
+		return status, nil
+	}
+
+	switch desired {
+	case PowerStateRunning:
+		action := "resume"
+		if status.Status == "stopped" {
+			action = "start"
+		}
+		if err := c.runGuestAction(ctx, node, vmType, vmid, action, nil); err != nil {
+			return nil, err
+		}
+
+	case PowerStateStopped:
+		if status.Status == "stopped" {
+			return nil, fmt.Errorf("%s %d is hibernated, start it before stopping", vmType, vmid)
+		}
+		if current == PowerStateRunning {
+			if _, err := c.powerOff(ctx, node, vmType, vmid, nil); err != nil {
+				return nil, err
+			}
+		} else if err := c.runGuestAction(ctx, node, vmType, vmid, "stop", nil); err != nil {
+			// A paused or suspended guest cannot react to a shutdown request
+			return nil, err
+		}
+
+	case PowerStatePaused:
+		if status.Status == "stopped" {
+			if err := c.runGuestAction(ctx, node, vmType, vmid, "start", nil); err != nil {
+				return nil, err
+			}
+		} else if current != PowerStateRunning {
+			if err := c.runGuestAction(ctx, node, vmType, vmid, "resume", nil); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.runGuestAction(ctx, node, vmType, vmid, "suspend", nil); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported desired power state: %s", desired)
+	}
+
+	return c.waitGuestStatus(ctx, node, vmType, vmid, func(st *VMStatus) bool {
+		return guestPowerState(st) == desired
+	})
+}
+
+// runGuestAction posts a status command and waits for its task to finish
+func (c *Client) runGuestAction(ctx context.Context, node, vmType string, vmid int, action string, params map[string]any) error {
+	task, err := c.guestAction(ctx, node, vmType, vmid, action, params)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Tasks.Wait(ctx, task)
+	return err
+}
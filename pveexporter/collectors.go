@@ -0,0 +1,241 @@
+package pveexporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	pve "github.com/ysicing/go-pve"
+)
+
+// boolValue converts a condition to a gauge value
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// collectNodes exports node usage from the cluster resources
+func collectNodes(ctx context.Context, s *scrape) error {
+	var nodes []*pve.NodeResource
+	err := s.call(ctx, func() (err error) {
+		nodes, err = s.client.Cluster.NodeResources(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, n := range nodes {
+		s.gauge("node_up", "Whether the node is online.", boolValue(n.Status == "online"), "node", n.Node)
+		if n.Status != "online" {
+			continue
+		}
+		s.gauge("node_cpu_usage_ratio", "CPU usage of the node (0-1).", n.CPU, "node", n.Node)
+		s.gauge("node_cpus", "Number of CPUs of the node.", n.MaxCPU, "node", n.Node)
+		s.gauge("node_memory_used_bytes", "Used memory of the node.", float64(n.Mem), "node", n.Node)
+		s.gauge("node_memory_total_bytes", "Total memory of the node.", float64(n.MaxMem), "node", n.Node)
+		s.gauge("node_rootfs_used_bytes", "Used space of the node root filesystem.", float64(n.Disk), "node", n.Node)
+		s.gauge("node_rootfs_total_bytes", "Size of the node root filesystem.", float64(n.MaxDisk), "node", n.Node)
+		s.gauge("node_uptime_seconds", "Uptime of the node.", float64(n.Uptime), "node", n.Node)
+	}
+
+	return nil
+}
+
+// collectGuests exports guest state and usage from the cluster resources
+func collectGuests(ctx context.Context, s *scrape) error {
+	guests, err := s.vmResources(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range guests {
+		id := strconv.Itoa(g.VMID)
+		s.gauge("guest_info", "Guest information.", 1,
+			"vmid", id, "name", g.Name, "node", g.Node, "type", g.Type,
+			"pool", g.Pool, "tags", g.Tags.String(), "template", strconv.Itoa(g.Template))
+		s.gauge("guest_up", "Whether the guest is running.", boolValue(g.Status == "running"), "vmid", id)
+		if g.HAState != "" {
+			s.gauge("guest_ha_state", "HA state of the guest.", 1, "vmid", id, "state", g.HAState)
+		}
+		if g.Lock != "" {
+			s.gauge("guest_lock", "Lock held on the guest.", 1, "vmid", id, "lock", g.Lock)
+		}
+
+		s.gauge("guest_cpu_usage_ratio", "CPU usage of the guest relative to its CPUs (0-1).", g.CPU, "vmid", id)
+		s.gauge("guest_cpus", "Number of CPUs of the guest.", g.MaxCPU, "vmid", id)
+		s.gauge("guest_memory_used_bytes", "Used memory of the guest.", float64(g.Mem), "vmid", id)
+		s.gauge("guest_memory_total_bytes", "Configured memory of the guest.", float64(g.MaxMem), "vmid", id)
+		s.gauge("guest_disk_used_bytes", "Used disk space of the guest (containers only).", float64(g.Disk), "vmid", id)
+		s.gauge("guest_disk_total_bytes", "Size of the guest root disk.", float64(g.MaxDisk), "vmid", id)
+		s.counter("guest_network_receive_bytes_total", "Bytes received by the guest.", float64(g.NetIn), "vmid", id)
+		s.counter("guest_network_transmit_bytes_total", "Bytes sent by the guest.", float64(g.NetOut), "vmid", id)
+		s.counter("guest_disk_read_bytes_total", "Bytes read by the guest.", float64(g.DiskRead), "vmid", id)
+		s.counter("guest_disk_write_bytes_total", "Bytes written by the guest.", float64(g.DiskWrite), "vmid", id)
+		s.gauge("guest_uptime_seconds", "Uptime of the guest.", float64(g.Uptime), "vmid", id)
+	}
+
+	return nil
+}
+
+// collectStorage exports storage usage per node
+func collectStorage(ctx context.Context, s *scrape) error {
+	var storages []*pve.StorageResource
+	err := s.call(ctx, func() (err error) {
+		storages, err = s.client.Cluster.StorageResources(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, st := range storages {
+		labels := []string{"storage", st.Storage, "node", st.Node}
+		s.gauge("storage_info", "Storage information.", 1,
+			"storage", st.Storage, "node", st.Node, "type", st.PluginType, "shared", strconv.Itoa(st.Shared))
+		s.gauge("storage_up", "Whether the storage is available.", boolValue(st.Status == "available"), labels...)
+		s.gauge("storage_used_bytes", "Used space of the storage.", float64(st.Disk), labels...)
+		s.gauge("storage_total_bytes", "Size of the storage.", float64(st.MaxDisk), labels...)
+	}
+
+	return nil
+}
+
+// collectHA exports the HA manager status
+func collectHA(ctx context.Context, s *scrape) error {
+	var entries []*struct {
+		ID        string `json:"id"`
+		Type      string `json:"type"`
+		Node      string `json:"node"`
+		SID       string `json:"sid"`
+		State     string `json:"state"`
+		Status    string `json:"status"`
+		Timestamp int64  `json:"timestamp"`
+		Quorate   any    `json:"quorate"`
+	}
+	if err := s.get(ctx, "cluster/ha/status/current", &entries); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		switch e.Type {
+		case "quorum":
+			quorate := fmt.Sprint(e.Quorate)
+			s.gauge("ha_quorate", "Whether the HA manager sees a quorate cluster.", boolValue(quorate == "1" || quorate == "true"))
+		case "lrm":
+			s.gauge("ha_lrm_mode", "Mode of the local resource manager of a node.", 1, "node", e.Node, "mode", lrmMode(e.Status))
+			if e.Timestamp > 0 {
+				s.gauge("ha_lrm_timestamp_seconds", "Time of the last status update of the local resource manager.", float64(e.Timestamp), "node", e.Node)
+			}
+		case "service":
+			s.gauge("ha_resource_state", "State of an HA resource.", 1, "sid", e.SID, "node", e.Node, "state", e.State)
+		}
+	}
+
+	return nil
+}
+
+// lrmMode extracts the mode from an LRM status such as
+// "node1 (active, Sat Oct 18 10:00:00 2026)". A stale LRM is reported as dead.
+func lrmMode(status string) string {
+	_, rest, ok := strings.Cut(status, "(")
+	if !ok {
+		return "unknown"
+	}
+	mode, _, _ := strings.Cut(strings.TrimSuffix(rest, ")"), ",")
+	mode = strings.TrimSpace(mode)
+	if strings.HasPrefix(mode, "old timestamp") {
+		return "dead"
+	}
+
+	return mode
+}
+
+// collectReplication exports the replication jobs of all online nodes
+func collectReplication(ctx context.Context, s *scrape) error {
+	var nodes []*pve.NodeResource
+	err := s.call(ctx, func() (err error) {
+		nodes, err = s.client.Cluster.NodeResources(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for _, n := range nodes {
+		if n.Status != "online" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(node string) {
+			defer wg.Done()
+
+			var jobs []*struct {
+				ID        string  `json:"id"`
+				Guest     int     `json:"guest"`
+				Target    string  `json:"target"`
+				LastSync  int64   `json:"last_sync"`
+				NextSync  int64   `json:"next_sync"`
+				Duration  float64 `json:"duration"`
+				FailCount int     `json:"fail_count"`
+				Error     string  `json:"error"`
+			}
+			if err := s.get(ctx, fmt.Sprintf("nodes/%s/replication", node), &jobs); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+
+			for _, j := range jobs {
+				labels := []string{"id", j.ID, "guest", strconv.Itoa(j.Guest), "source", node, "target", j.Target}
+				s.gauge("replication_last_sync_timestamp_seconds", "Time of the last successful sync.", float64(j.LastSync), labels...)
+				s.gauge("replication_next_sync_timestamp_seconds", "Time of the next scheduled sync.", float64(j.NextSync), labels...)
+				s.gauge("replication_duration_seconds", "Duration of the last sync.", j.Duration, labels...)
+				s.gauge("replication_failures", "Number of consecutive failed syncs.", float64(j.FailCount), labels...)
+			}
+		}(n.Node)
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// collectBackup exports whether guests are covered by a backup job
+func collectBackup(ctx context.Context, s *scrape) error {
+	guests, err := s.vmResources(ctx)
+	if err != nil {
+		return err
+	}
+
+	var uncovered []*struct {
+		VMID int `json:"vmid"`
+	}
+	if err := s.get(ctx, "cluster/backup-info/not-backed-up", &uncovered); err != nil {
+		return err
+	}
+
+	missing := map[int]bool{}
+	for _, g := range uncovered {
+		missing[g.VMID] = true
+	}
+
+	for _, g := range guests.Guests() {
+		s.gauge("guest_backup_covered", "Whether the guest is included in a backup job.", boolValue(!missing[g.VMID]), "vmid", strconv.Itoa(g.VMID))
+	}
+	s.gauge("backup_uncovered_guests", "Number of guests not included in any backup job.", float64(len(uncovered)))
+
+	return nil
+}
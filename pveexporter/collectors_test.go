@@ -0,0 +1,23 @@
+package pveexporter
+
+import "testing"
+
+func TestLRMMode(t *testing.T) {
+	tests := []struct {
+		status string
+		want   string
+	}{
+		{"pve1 (active, Sat Oct 18 10:00:00 2026)", "active"},
+		{"pve2 (idle, Sat Oct 18 10:00:00 2026)", "idle"},
+		{"pve3 (maintenance mode, Sat Oct 18 10:00:00 2026)", "maintenance mode"},
+		{"pve4 (old timestamp - dead?, Sat Oct 18 09:00:00 2026)", "dead"},
+		{"pve5 (wait_for_agent_lock)", "wait_for_agent_lock"},
+		{"unexpected", "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := lrmMode(tt.status); got != tt.want {
+			t.Errorf("lrmMode(%q) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
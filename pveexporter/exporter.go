@@ -0,0 +1,199 @@
+// Package pveexporter exports Proxmox VE metrics in the Prometheus text
+// exposition format. An Exporter serves them over HTTP and can be mounted on
+// any mux:
+//
+//	http.Handle("/metrics", pveexporter.New(client, nil))
+package pveexporter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	pve "github.com/ysicing/go-pve"
+)
+
+const (
+	// namespace prefixes all metric names
+	namespace = "pve"
+
+	defaultTimeout     = 10 * time.Second
+	defaultConcurrency = 4
+)
+
+// Collector names
+const (
+	CollectorNode        = "node"
+	CollectorGuest       = "guest"
+	CollectorStorage     = "storage"
+	CollectorHA          = "ha"
+	CollectorReplication = "replication"
+	CollectorBackup      = "backup"
+)
+
+// collectors maps collector names to their implementation
+var collectors = map[string]func(ctx context.Context, s *scrape) error{
+	CollectorNode:        collectNodes,
+	CollectorGuest:       collectGuests,
+	CollectorStorage:     collectStorage,
+	CollectorHA:          collectHA,
+	CollectorReplication: collectReplication,
+	CollectorBackup:      collectBackup,
+}
+
+// Options configures an Exporter
+type Options struct {
+	Timeout     time.Duration // Time a scrape may take (default 10s)
+	Concurrency int           // Maximum number of parallel API requests (default 4)
+	Collectors  []string      // Collectors to run (default all)
+}
+
+// Exporter collects PVE metrics on each scrape
+type Exporter struct {
+	client      *pve.Client
+	timeout     time.Duration
+	concurrency int
+	collectors  []string
+}
+
+// New creates an exporter for client
+func New(client *pve.Client, opts *Options) *Exporter {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	e := &Exporter{
+		client:      client,
+		timeout:     opts.Timeout,
+		concurrency: opts.Concurrency,
+		collectors:  opts.Collectors,
+	}
+	if e.timeout <= 0 {
+		e.timeout = defaultTimeout
+	}
+	if e.concurrency <= 0 {
+		e.concurrency = defaultConcurrency
+	}
+	if len(e.collectors) == 0 {
+		for name := range collectors {
+			e.collectors = append(e.collectors, name)
+		}
+	}
+
+	return e
+}
+
+// ServeHTTP runs a scrape and writes the metrics
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := e.Scrape(r.Context(), &buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// Scrape runs all collectors in parallel and writes their metrics to w. A
+// failing collector does not fail the scrape; it is reported through
+// pve_scrape_collector_success.
+func (e *Exporter) Scrape(ctx context.Context, w io.Writer) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	s := &scrape{
+		client:   e.client,
+		registry: newRegistry(),
+		sem:      make(chan struct{}, e.concurrency),
+	}
+
+	var wg sync.WaitGroup
+	for _, name := range e.collectors {
+		collect, ok := collectors[name]
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+
+			start := time.Now()
+			success := 1.0
+			if err := collect(ctx, s); err != nil {
+				success = 0
+			}
+
+			s.gauge("scrape_collector_success", "Whether the collector succeeded.", success, "collector", name)
+			s.gauge("scrape_collector_duration_seconds", "Duration of the collector.", time.Since(start).Seconds(), "collector", name)
+		}(name)
+	}
+	wg.Wait()
+
+	return s.registry.write(w)
+}
+
+// scrape holds the state of a single scrape
+type scrape struct {
+	client   *pve.Client
+	registry *registry
+	sem      chan struct{}
+
+	guestsOnce sync.Once
+	guests     pve.VMResources
+	guestsErr  error
+}
+
+// call runs an API request, limiting the number of parallel requests
+func (s *scrape) call(ctx context.Context, fn func() error) error {
+	select {
+	case s.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-s.sem }()
+
+	return fn()
+}
+
+// vmResources retrieves the guests once per scrape
+func (s *scrape) vmResources(ctx context.Context) (pve.VMResources, error) {
+	s.guestsOnce.Do(func() {
+		s.guestsErr = s.call(ctx, func() (err error) {
+			s.guests, err = s.client.Cluster.VMResources(ctx)
+			return err
+		})
+	})
+
+	return s.guests, s.guestsErr
+}
+
+// get retrieves an API path and decodes its data into v
+func (s *scrape) get(ctx context.Context, path string, v any) error {
+	return s.call(ctx, func() error {
+		req, err := s.client.NewRequest("GET", path, nil, pve.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+
+		result := struct {
+			Data any `json:"data"`
+		}{Data: v}
+		_, err = s.client.Do(req, &result)
+		return err
+	})
+}
+
+// gauge adds a namespaced gauge
+func (s *scrape) gauge(name, help string, value float64, labels ...string) {
+	s.registry.gauge(namespace+"_"+name, help, value, labels...)
+}
+
+// counter adds a namespaced counter
+func (s *scrape) counter(name, help string, value float64, labels ...string) {
+	s.registry.counter(namespace+"_"+name, help, value, labels...)
+}
@@ -0,0 +1,120 @@
+package pveexporter
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// family is a metric family in the Prometheus text exposition format
+type family struct {
+	name    string
+	help    string
+	typ     string // gauge or counter
+	samples []sample
+}
+
+// sample is a single metric of a family
+type sample struct {
+	labels string // Rendered label set, e.g. {node="pve1"}
+	value  float64
+}
+
+// registry collects the metrics of a scrape
+type registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+func newRegistry() *registry {
+	return &registry{families: map[string]*family{}}
+}
+
+// gauge adds a gauge sample; labels are name/value pairs
+func (r *registry) gauge(name, help string, value float64, labels ...string) {
+	r.add(name, help, "gauge", value, labels)
+}
+
+// counter adds a counter sample; labels are name/value pairs
+func (r *registry) counter(name, help string, value float64, labels ...string) {
+	r.add(name, help, "counter", value, labels)
+}
+
+func (r *registry) add(name, help, typ string, value float64, labels []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{name: name, help: help, typ: typ}
+		r.families[name] = f
+	}
+	f.samples = append(f.samples, sample{labels: renderLabels(labels), value: value})
+}
+
+// write renders all families sorted by name
+func (r *registry) write(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		f := r.families[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.typ)
+
+		sort.SliceStable(f.samples, func(i, j int) bool { return f.samples[i].labels < f.samples[j].labels })
+		for _, s := range f.samples {
+			b.WriteString(f.name + s.labels + " " + formatValue(s.value) + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// renderLabels renders name/value pairs as a label set
+func renderLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		parts = append(parts, labels[i]+`="`+escapeLabel(labels[i+1])+`"`)
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+
+// formatValue formats a sample value, including the special float values
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
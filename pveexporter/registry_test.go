@@ -0,0 +1,96 @@
+package pveexporter
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestRenderLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels []string
+		want   string
+	}{
+		{"none", nil, ""},
+		{"single", []string{"node", "pve1"}, `{node="pve1"}`},
+		{"multiple", []string{"node", "pve1", "vmid", "100"}, `{node="pve1",vmid="100"}`},
+		{"quote", []string{"name", `say "hi"`}, `{name="say \"hi\""}`},
+		{"backslash", []string{"path", `C:\temp`}, `{path="C:\\temp"}`},
+		{"newline", []string{"desc", "a\nb"}, `{desc="a\nb"}`},
+		{"escaped quote", []string{"v", `\"`}, `{v="\\\""}`},
+		{"odd pair dropped", []string{"node", "pve1", "dangling"}, `{node="pve1"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderLabels(tt.labels); got != tt.want {
+				t.Errorf("renderLabels() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeHelp(t *testing.T) {
+	tests := []struct {
+		help string
+		want string
+	}{
+		{"Uptime of the node.", "Uptime of the node."},
+		{`Quotes "stay" as is.`, `Quotes "stay" as is.`},
+		{`back\slash`, `back\\slash`},
+		{"two\nlines", `two\nlines`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeHelp(tt.help); got != tt.want {
+			t.Errorf("escapeHelp(%q) = %q, want %q", tt.help, got, tt.want)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0, "0"},
+		{1, "1"},
+		{0.25, "0.25"},
+		{-3.5, "-3.5"},
+		{1234567890123, "1.234567890123e+12"},
+		{math.NaN(), "NaN"},
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+	}
+
+	for _, tt := range tests {
+		if got := formatValue(tt.value); got != tt.want {
+			t.Errorf("formatValue(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRegistryWrite(t *testing.T) {
+	r := newRegistry()
+	r.gauge("pve_up", "Whether the node is online.", 1, "node", "pve2")
+	r.gauge("pve_up", "Whether the node is online.", 0, "node", "pve1")
+	r.counter("pve_net_in_bytes_total", "Received bytes.\nPer guest.", 42, "vmid", "100", "name", `web "1"`)
+
+	var b strings.Builder
+	if err := r.write(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP pve_net_in_bytes_total Received bytes.\nPer guest.
+# TYPE pve_net_in_bytes_total counter
+pve_net_in_bytes_total{vmid="100",name="web \"1\""} 42
+# HELP pve_up Whether the node is online.
+# TYPE pve_up gauge
+pve_up{node="pve1"} 0
+pve_up{node="pve2"} 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("write() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -1,9 +1,17 @@
 package pve
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
+// snapshotCancelWait bounds how long ConsistentSnapshot keeps waiting for the
+// snapshot task after ctx was cancelled
+const snapshotCancelWait = 5 * time.Minute
+
 // QEMUService handles QEMU-specific VM operations
 type QEMUService struct {
 	client *Client
@@ -57,7 +65,12 @@ func (s *QEMUService) GetStatus(node string, vmid int) (*VMStatus, error) {
 
 // GetConfig retrieves QEMU VM configuration
 func (s *QEMUService) GetConfig(node string, vmid int) (*VMConfig, error) {
-	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/config", node, vmid), nil)
+	return s.getConfig(context.Background(), node, vmid)
+}
+
+// getConfig retrieves QEMU VM configuration within ctx
+func (s *QEMUService) getConfig(ctx context.Context, node string, vmid int) (*VMConfig, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/config", node, vmid), nil, WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -237,45 +250,7 @@ func (s *QEMUService) Delete(node string, vmid int) (*Task, error) {
 
 // Migrate migrates a QEMU VM to another node
 func (s *QEMUService) Migrate(node string, vmid int, target string, options *MigrateOptions) (*Task, error) {
-	params := map[string]any{
-		"target": target,
-	}
-
-	if options != nil {
-		if options.Online {
-			params["online"] = 1
-		}
-		if options.Force {
-			params["force"] = 1
-		}
-		if options.MigrationNetwork != "" {
-			params["migration_network"] = options.MigrationNetwork
-		}
-		if options.BWLimit > 0 {
-			params["bwlimit"] = options.BWLimit
-		}
-		if options.TargetStorage != "" {
-			params["targetstorage"] = options.TargetStorage
-		}
-		if options.Delete {
-			params["delete"] = 1
-		}
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/qemu/%d/migrate", node, vmid), params)
-	if err != nil {
-		return nil, err
-	}
-
-	var result struct {
-		Data *Task
-	}
-	_, err = s.client.Do(req, &result)
-	if err != nil {
-		return nil, err
-	}
-
-	return result.Data, nil
+	return s.client.migrate(context.Background(), node, "qemu", vmid, target, options)
 }
 
 // Clone clones a QEMU VM
@@ -407,6 +382,98 @@ func (s *QEMUService) RollbackSnapshot(node string, vmid int, snapshotName strin
 	return result.Data, nil
 }
 
+// ConsistentSnapshot creates an application-consistent snapshot of a QEMU VM.
+// The guest agent must be enabled and responding: PVE's snapshot task then
+// freezes the guest filesystems before the snapshot and thaws them afterwards.
+// The task only logs a warning when the freeze fails, so its log is checked
+// and ErrFreezeFailed is returned in that case. Cancelling ctx does not stop
+// the task; it is waited for up to snapshotCancelWait longer so that the guest
+// is never thawed while the snapshot is still being taken. Filesystems left
+// frozen after the task are thawed.
+func (s *QEMUService) ConsistentSnapshot(ctx context.Context, node string, vmid int, name, description string) (*ConsistentSnapshotResult, error) {
+	if _, err := s.AgentAvailable(ctx, node, vmid); err != nil {
+		return nil, err
+	}
+
+	// The task would thaw a freeze held by someone else
+	status, err := s.AgentFSFreezeStatus(ctx, node, vmid)
+	if err != nil {
+		return nil, err
+	}
+	if status != "thawed" {
+		return nil, fmt.Errorf("guest filesystems are %s", status)
+	}
+
+	// The agent freezes the same mounts that get-fsinfo reports
+	filesystems, err := s.agentFilesystemInfo(ctx, node, vmid)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.CreateSnapshot(node, vmid, name, description, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ConsistentSnapshotResult{}
+	result.Task, err = s.client.Tasks.Wait(ctx, task)
+	taskErr := err
+	if err != nil && ctx.Err() != nil {
+		waitCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), snapshotCancelWait)
+		status, waitErr := s.client.Tasks.Wait(waitCtx, task)
+		cancel()
+		if status == nil || status.Status != "stopped" {
+			return result, fmt.Errorf("%w: snapshot task %s is still running", err, task.UPID)
+		}
+		result.Task, taskErr = status, waitErr
+	}
+
+	// The task has ended, cleanup must not be cancelled
+	cleanupCtx := context.WithoutCancel(ctx)
+
+	status, statusErr := s.AgentFSFreezeStatus(cleanupCtx, node, vmid)
+	if statusErr != nil {
+		err = errors.Join(err, fmt.Errorf("freeze status: %w", statusErr))
+	} else if status == "frozen" {
+		thawed, thawErr := s.AgentFSThaw(cleanupCtx, node, vmid)
+		result.Thawed = thawed
+		if thawErr != nil {
+			err = errors.Join(err, fmt.Errorf("thaw filesystems: %w", thawErr))
+		}
+	}
+	if taskErr != nil {
+		return result, err
+	}
+
+	// The snapshot exists, report whether it was taken with frozen filesystems
+	log, logErr := s.client.Tasks.nodeTaskLog(cleanupCtx, task.Node, task.UPID)
+	if logErr != nil {
+		return result, errors.Join(err, fmt.Errorf("read snapshot task log: %w", logErr))
+	}
+	if line := freezeFailure(log); line != "" {
+		return result, errors.Join(err, fmt.Errorf("%w: %s", ErrFreezeFailed, line))
+	}
+
+	for _, fs := range filesystems {
+		result.Filesystems = append(result.Filesystems, fs.Mountpoint)
+	}
+	result.Frozen = len(result.Filesystems)
+
+	return result, err
+}
+
+// freezeFailure returns the snapshot task log line reporting a failed guest
+// filesystem freeze, or an empty string
+func freezeFailure(log []string) string {
+	for _, line := range log {
+		if strings.Contains(line, "guest-fsfreeze-freeze problems") || strings.Contains(line, "unable to freeze guest fs") {
+			return line
+		}
+	}
+
+	return ""
+}
+
 // GetVNCProxy gets VNC proxy information for a QEMU VM
 func (s *QEMUService) GetVNCProxy(node string, vmid int, websocket bool) (map[string]any, error) {
 	params := map[string]any{}
@@ -491,7 +558,12 @@ func (s *QEMUService) GetAgentNetworkInterfaces(node string, vmid int) ([]Networ
 
 // GetAgentFilesystemInfo retrieves filesystem information via QEMU guest agent
 func (s *QEMUService) GetAgentFilesystemInfo(node string, vmid int) ([]FilesystemInfo, error) {
-	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/get-fsinfo", node, vmid), nil)
+	return s.agentFilesystemInfo(context.Background(), node, vmid)
+}
+
+// agentFilesystemInfo implements GetAgentFilesystemInfo with a context
+func (s *QEMUService) agentFilesystemInfo(ctx context.Context, node string, vmid int) ([]FilesystemInfo, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/get-fsinfo", node, vmid), nil, WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,32 @@
+package pve
+
+import "testing"
+
+func TestFreezeFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		log  []string
+		want string
+	}{
+		{"no log", nil, ""},
+		{"clean", []string{"snapshotting 'drive-scsi0' (local-lvm:vm-100-disk-0)", "TASK OK"}, ""},
+		{
+			"freeze problems",
+			[]string{"guest-fsfreeze-freeze problems - got timeout", "TASK OK"},
+			"guest-fsfreeze-freeze problems - got timeout",
+		},
+		{
+			"unable to freeze",
+			[]string{"unable to freeze guest fs - got timeout", "TASK OK"},
+			"unable to freeze guest fs - got timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := freezeFailure(tt.log); got != tt.want {
+				t.Errorf("freezeFailure() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package pve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RRDTimeframe is the time frame of RRD statistics
+type RRDTimeframe string
+
+const (
+	RRDHour   RRDTimeframe = "hour"
+	RRDDay    RRDTimeframe = "day"
+	RRDWeek   RRDTimeframe = "week"
+	RRDMonth  RRDTimeframe = "month"
+	RRDYear   RRDTimeframe = "year"
+	RRDDecade RRDTimeframe = "decade" // Nodes only
+)
+
+// RRDConsolidation is the function used to consolidate RRD data points
+type RRDConsolidation string
+
+const (
+	RRDAverage RRDConsolidation = "AVERAGE"
+	RRDMax     RRDConsolidation = "MAX"
+)
+
+// Common RRD metric names. Which metrics are present depends on the resource
+// type and the PVE version.
+const (
+	MetricCPU                = "cpu"
+	MetricMaxCPU             = "maxcpu"
+	MetricMem                = "mem"
+	MetricMaxMem             = "maxmem"
+	MetricMemUsed            = "memused"
+	MetricMemTotal           = "memtotal"
+	MetricSwapUsed           = "swapused"
+	MetricSwapTotal          = "swaptotal"
+	MetricRootUsed           = "rootused"
+	MetricRootTotal          = "roottotal"
+	MetricDisk               = "disk"
+	MetricMaxDisk            = "maxdisk"
+	MetricNetIn              = "netin"
+	MetricNetOut             = "netout"
+	MetricDiskRead           = "diskread"
+	MetricDiskWrite          = "diskwrite"
+	MetricLoadAvg            = "loadavg"
+	MetricIOWait             = "iowait"
+	MetricPressureCPUSome    = "pressurecpusome"
+	MetricPressureCPUFull    = "pressurecpufull"
+	MetricPressureIOSome     = "pressureiosome"
+	MetricPressureIOFull     = "pressureiofull"
+	MetricPressureMemorySome = "pressurememorysome"
+	MetricPressureMemoryFull = "pressurememoryfull"
+	MetricStorageUsed        = "used"
+	MetricStorageTotal       = "total"
+)
+
+// Sample is a single RRD data point. Metrics without data for the point in
+// time are missing from Values.
+type Sample struct {
+	Time   time.Time
+	Values map[string]float64
+}
+
+// Value returns a metric of the sample and whether it is present
+func (s Sample) Value(metric string) (float64, bool) {
+	v, ok := s.Values[metric]
+	return v, ok
+}
+
+// UnmarshalJSON decodes an rrddata entry
+func (s *Sample) UnmarshalJSON(data []byte) error {
+	var raw map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	*s = Sample{Values: map[string]float64{}}
+	for key, value := range raw {
+		n, ok := value.(json.Number)
+		if !ok {
+			continue
+		}
+		if key == "time" {
+			sec, err := n.Int64()
+			if err != nil {
+				return fmt.Errorf("invalid RRD time %q: %w", n, err)
+			}
+			s.Time = time.Unix(sec, 0)
+			continue
+		}
+		if v, err := n.Float64(); err == nil {
+			s.Values[key] = v
+		}
+	}
+
+	return nil
+}
+
+// RRDData retrieves RRD statistics of a node
+func (s *NodesService) RRDData(ctx context.Context, name string, timeframe RRDTimeframe, cf RRDConsolidation) ([]Sample, error) {
+	return s.client.rrdData(ctx, fmt.Sprintf("nodes/%s", name), timeframe, cf)
+}
+
+// RRDData retrieves RRD statistics of a QEMU VM
+func (s *QEMUService) RRDData(ctx context.Context, node string, vmid int, timeframe RRDTimeframe, cf RRDConsolidation) ([]Sample, error) {
+	return s.client.rrdData(ctx, fmt.Sprintf("nodes/%s/qemu/%d", node, vmid), timeframe, cf)
+}
+
+// RRDData retrieves RRD statistics of an LXC container
+func (s *LXCService) RRDData(ctx context.Context, node string, vmid int, timeframe RRDTimeframe, cf RRDConsolidation) ([]Sample, error) {
+	return s.client.rrdData(ctx, fmt.Sprintf("nodes/%s/lxc/%d", node, vmid), timeframe, cf)
+}
+
+// RRDData retrieves RRD statistics of a storage on a node
+func (s *StorageService) RRDData(ctx context.Context, node, storage string, timeframe RRDTimeframe, cf RRDConsolidation) ([]Sample, error) {
+	return s.client.rrdData(ctx, fmt.Sprintf("nodes/%s/storage/%s", node, storage), timeframe, cf)
+}
+
+// rrdData retrieves the rrddata below path
+func (c *Client) rrdData(ctx context.Context, path string, timeframe RRDTimeframe, cf RRDConsolidation) ([]Sample, error) {
+	params := map[string]string{
+		"timeframe": string(timeframe),
+	}
+	if cf != "" {
+		params["cf"] = string(cf)
+	}
+
+	req, err := c.NewRequest("GET", path+"/rrddata", params, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []Sample
+	}
+	_, err = c.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
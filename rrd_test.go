@@ -0,0 +1,136 @@
+package pve
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// rrdPayload is a node rrddata response. The newest point has no data yet,
+// PVE omits its metrics.
+const rrdPayload = `{"data":[
+{"time":1760781600,"cpu":0.0123456789,"maxcpu":8,"loadavg":0.35,"iowait":0.00125,
+"memused":4123456789.33333,"memtotal":33525383168,"swapused":0,"swaptotal":8589930496,
+"netin":12345.6,"netout":9876.5,"rootused":5233664000,"roottotal":100861726720,
+"pressurecpusome":0.42,"pressurememoryfull":0},
+{"time":1760781660,"cpu":0.02,"maxcpu":8},
+{"time":1760781720}
+]}`
+
+func TestSampleUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Sample
+		wantErr bool
+	}{
+		{
+			name: "metrics",
+			data: `{"time":1760781600,"cpu":0.5,"maxmem":2147483648,"netin":1.5e3}`,
+			want: Sample{Time: time.Unix(1760781600, 0), Values: map[string]float64{"cpu": 0.5, "maxmem": 2147483648, "netin": 1500}},
+		},
+		{
+			name: "no data",
+			data: `{"time":1760781720}`,
+			want: Sample{Time: time.Unix(1760781720, 0), Values: map[string]float64{}},
+		},
+		{
+			name: "non-numeric ignored",
+			data: `{"time":1760781600,"cpu":0.5,"name":"pve1","cpu2":null}`,
+			want: Sample{Time: time.Unix(1760781600, 0), Values: map[string]float64{"cpu": 0.5}},
+		},
+		{
+			name:    "fractional time",
+			data:    `{"time":1760781600.5}`,
+			wantErr: true,
+		},
+		{
+			name:    "not an object",
+			data:    `[1,2]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Sample
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (!got.Time.Equal(tt.want.Time) || !reflect.DeepEqual(got.Values, tt.want.Values)) {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRRDData(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api2/json/nodes/pve1/rrddata" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+		w.Write([]byte(rrdPayload))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, &AuthOptions{Username: "root@pam", TokenID: "test", TokenSecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	samples, err := client.Nodes.RRDData(context.Background(), "pve1", RRDHour, RRDMax)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "cf=MAX&timeframe=hour" {
+		t.Errorf("query = %q, want %q", query, "cf=MAX&timeframe=hour")
+	}
+	if len(samples) != 3 {
+		t.Fatalf("got %d samples, want 3", len(samples))
+	}
+
+	first := samples[0]
+	if !first.Time.Equal(time.Unix(1760781600, 0)) {
+		t.Errorf("Time = %v, want %v", first.Time, time.Unix(1760781600, 0))
+	}
+	for metric, want := range map[string]float64{
+		MetricCPU:                0.0123456789,
+		MetricMaxCPU:             8,
+		MetricLoadAvg:            0.35,
+		MetricMemUsed:            4123456789.33333,
+		MetricSwapUsed:           0,
+		MetricPressureCPUSome:    0.42,
+		MetricPressureMemoryFull: 0,
+	} {
+		if got, ok := first.Value(metric); !ok || got != want {
+			t.Errorf("Value(%q) = %v, %v, want %v, true", metric, got, ok, want)
+		}
+	}
+	if _, ok := samples[1].Value(MetricMemUsed); ok {
+		t.Errorf("Value(%q) present in sample without it", MetricMemUsed)
+	}
+	if n := len(samples[2].Values); n != 0 {
+		t.Errorf("sample without data has %d values, want 0", n)
+	}
+
+	// The deprecated GetRRD returns the same averaged samples
+	rrd, err := client.Nodes.GetRRD("pve1", "day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "cf=AVERAGE&timeframe=day" {
+		t.Errorf("query = %q, want %q", query, "cf=AVERAGE&timeframe=day")
+	}
+	if len(rrd) != len(samples) {
+		t.Errorf("GetRRD() returned %d samples, want %d", len(rrd), len(samples))
+	}
+}
@@ -0,0 +1,274 @@
+package pve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// defaultServiceRestartTimeout is the time a node gets to become healthy after a restart
+const defaultServiceRestartTimeout = 2 * time.Minute
+
+// ServiceStatus represents a systemd service of a node
+type ServiceStatus struct {
+	Service     string `json:"service"` // Unit name, e.g. pveproxy
+	Name        string `json:"name"`
+	Desc        string `json:"desc"`
+	State       string `json:"state"`        // systemd SubState, e.g. running
+	ActiveState string `json:"active-state"` // systemd ActiveState, e.g. active
+	UnitState   string `json:"unit-state"`   // systemd UnitFileState, e.g. enabled
+}
+
+// Running reports whether the service is active and running
+func (s *ServiceStatus) Running() bool {
+	return s.ActiveState == "active" && s.State == "running"
+}
+
+// ListServices retrieves the services of a node
+func (s *NodesService) ListServices(ctx context.Context, name string) ([]*ServiceStatus, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/services", name), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []*ServiceStatus
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// GetService retrieves the state of a service of a node
+func (s *NodesService) GetService(ctx context.Context, name, service string) (*ServiceStatus, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/services/%s/state", name, service), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *ServiceStatus
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// StartService starts a service of a node
+func (s *NodesService) StartService(ctx context.Context, name, service string) (*Task, error) {
+	return s.serviceCommand(ctx, name, service, "start")
+}
+
+// StopService stops a service of a node
+func (s *NodesService) StopService(ctx context.Context, name, service string) (*Task, error) {
+	return s.serviceCommand(ctx, name, service, "stop")
+}
+
+// RestartService hard-restarts a service of a node
+func (s *NodesService) RestartService(ctx context.Context, name, service string) (*Task, error) {
+	return s.serviceCommand(ctx, name, service, "restart")
+}
+
+// ReloadService reloads a service of a node, falling back to a restart if the
+// service cannot be reloaded
+func (s *NodesService) ReloadService(ctx context.Context, name, service string) (*Task, error) {
+	return s.serviceCommand(ctx, name, service, "reload")
+}
+
+// serviceCommand posts a command (start, stop, restart, reload) to a service
+func (s *NodesService) serviceCommand(ctx context.Context, name, service, command string) (*Task, error) {
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/services/%s/%s", name, service, command), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *Task
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// RollingRestartOptions specifies how a service is restarted across nodes
+type RollingRestartOptions struct {
+	Nodes   []string      // Nodes in restart order (default all nodes by name)
+	Reload  bool          // Reload instead of restart
+	Timeout time.Duration // Time a node gets to become healthy again (default 2m)
+	Settle  time.Duration // Pause after a node became healthy, before the next one
+
+	// Healthy is an additional check that must pass before moving on to the
+	// next node. It is polled until it returns nil or the timeout expires.
+	Healthy func(ctx context.Context, node string) error
+}
+
+// ServiceRestart is the outcome of restarting a service on a single node
+type ServiceRestart struct {
+	Node     string
+	Task     *Task
+	Duration time.Duration // Time until the node was healthy again
+	Err      error
+}
+
+// RollingRestart restarts a service on one node at a time. After each restart
+// it waits until the service is running, the node is online and the cluster is
+// quorate (and Healthy passes) before continuing. It stops at the first node
+// that does not become healthy and returns the results so far.
+func (s *NodesService) RollingRestart(ctx context.Context, service string, opts *RollingRestartOptions) ([]*ServiceRestart, error) {
+	if opts == nil {
+		opts = &RollingRestartOptions{}
+	}
+
+	nodes := opts.Nodes
+	if len(nodes) == 0 {
+		all, err := s.list(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range all {
+			nodes = append(nodes, node.Name)
+		}
+		sort.Strings(nodes)
+	}
+
+	// Do not start with a cluster that is already degraded
+	if err := s.clusterHealthy(ctx, nodes...); err != nil {
+		return nil, fmt.Errorf("cluster not healthy before restart: %w", err)
+	}
+
+	var results []*ServiceRestart
+	for i, node := range nodes {
+		result := s.restartOnNode(ctx, node, service, opts)
+		results = append(results, result)
+		if result.Err != nil {
+			return results, fmt.Errorf("node %s: %w", node, result.Err)
+		}
+
+		if opts.Settle > 0 && i < len(nodes)-1 {
+			select {
+			case <-ctx.Done():
+				return results, ctx.Err()
+			case <-time.After(opts.Settle):
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// restartOnNode restarts a service on a node and waits until the node is healthy
+func (s *NodesService) restartOnNode(ctx context.Context, node, service string, opts *RollingRestartOptions) *ServiceRestart {
+	result := &ServiceRestart{Node: node}
+	start := time.Now()
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultServiceRestartTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	command := "restart"
+	if opts.Reload {
+		command = "reload"
+	}
+	task, err := s.serviceCommand(ctx, node, service, command)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Task = task
+
+	// Restarting pveproxy or pvedaemon may drop the API connection while the
+	// task is polled. Only a task that stopped with an error is a failure,
+	// anything else is left to the health checks below.
+	if status, err := s.client.Tasks.Wait(ctx, task); err != nil && status != nil && status.Status == "stopped" {
+		result.Task = status
+		result.Err = err
+		return result
+	} else if status != nil {
+		result.Task = status
+	}
+
+	result.Err = s.waitHealthy(ctx, node, service, opts.Healthy)
+	result.Duration = time.Since(start)
+	return result
+}
+
+// waitHealthy polls until a service runs on a node and the cluster is healthy
+func (s *NodesService) waitHealthy(ctx context.Context, node, service string, healthy func(context.Context, string) error) error {
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+
+	for {
+		err := s.serviceHealthy(ctx, node, service)
+		if err == nil {
+			err = s.clusterHealthy(ctx, node)
+		}
+		if err == nil && healthy != nil {
+			err = healthy(ctx, node)
+		}
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// serviceHealthy checks that a service is running on a node
+func (s *NodesService) serviceHealthy(ctx context.Context, node, service string) error {
+	status, err := s.GetService(ctx, node, service)
+	if err != nil {
+		return err
+	}
+	if !status.Running() {
+		return fmt.Errorf("service %s is %s (%s)", service, status.ActiveState, status.State)
+	}
+
+	return nil
+}
+
+// clusterHealthy checks that the cluster is quorate and nodes are online.
+// Standalone nodes have no cluster entry and are always quorate.
+func (s *NodesService) clusterHealthy(ctx context.Context, nodes ...string) error {
+	status, err := s.client.Cluster.status(ctx)
+	if err != nil {
+		return err
+	}
+
+	online := map[string]bool{}
+	for _, entry := range status {
+		switch entry.Type {
+		case "cluster":
+			if entry.Quorate != 1 {
+				return errors.New("cluster is not quorate")
+			}
+		case "node":
+			online[entry.Name] = entry.Online == 1
+		}
+	}
+
+	for _, node := range nodes {
+		if ok, known := online[node]; known && !ok {
+			return fmt.Errorf("node %s is offline", node)
+		}
+	}
+
+	return nil
+}
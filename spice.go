@@ -0,0 +1,156 @@
+package pve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SpiceConfig holds the connection settings of a SPICE console as returned by
+// spiceproxy and spiceshell. WriteTo renders them as a virt-viewer .vv file.
+type SpiceConfig struct {
+	Type             string // Always spice
+	Title            string
+	Host             string // Ticket-encoded host the proxy connects to
+	Proxy            string // SPICE proxy URL, e.g. http://pve-node1:3128
+	TLSPort          int
+	Password         string
+	HostSubject      string // Certificate subject of the node
+	CA               string // CA certificate in PEM format
+	DeleteThisFile   bool
+	ToggleFullscreen string
+	ReleaseCursor    string
+	SecureAttention  string
+
+	// Extra holds settings without a dedicated field
+	Extra map[string]string
+}
+
+// UnmarshalJSON decodes a SPICE configuration whose values may be strings or numbers
+func (c *SpiceConfig) UnmarshalJSON(data []byte) error {
+	var raw map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	*c = SpiceConfig{}
+	for key, value := range raw {
+		s := fmt.Sprint(value)
+		switch key {
+		case "type":
+			c.Type = s
+		case "title":
+			c.Title = s
+		case "host":
+			c.Host = s
+		case "proxy":
+			c.Proxy = s
+		case "tls-port":
+			port, err := strconv.Atoi(s)
+			if err != nil {
+				return fmt.Errorf("invalid SPICE TLS port %q: %w", s, err)
+			}
+			c.TLSPort = port
+		case "password":
+			c.Password = s
+		case "host-subject":
+			c.HostSubject = s
+		case "ca":
+			c.CA = s
+		case "delete-this-file":
+			c.DeleteThisFile = s == "1"
+		case "toggle-fullscreen":
+			c.ToggleFullscreen = s
+		case "release-cursor":
+			c.ReleaseCursor = s
+		case "secure-attention":
+			c.SecureAttention = s
+		default:
+			if c.Extra == nil {
+				c.Extra = map[string]string{}
+			}
+			c.Extra[key] = s
+		}
+	}
+
+	return nil
+}
+
+// WriteTo writes the configuration as a virt-viewer .vv file
+func (c *SpiceConfig) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	b.WriteString("[virt-viewer]\n")
+
+	set := func(key, value string) {
+		if value != "" {
+			// Values are single lines, multi-line PEM data uses escaped newlines
+			value = strings.ReplaceAll(value, "\n", `\n`)
+			b.WriteString(key + "=" + value + "\n")
+		}
+	}
+
+	set("type", c.Type)
+	set("title", c.Title)
+	set("host", c.Host)
+	set("proxy", c.Proxy)
+	if c.TLSPort > 0 {
+		set("tls-port", strconv.Itoa(c.TLSPort))
+	}
+	set("password", c.Password)
+	set("host-subject", c.HostSubject)
+	set("ca", c.CA)
+	if c.DeleteThisFile {
+		set("delete-this-file", "1")
+	}
+	set("toggle-fullscreen", c.ToggleFullscreen)
+	set("release-cursor", c.ReleaseCursor)
+	set("secure-attention", c.SecureAttention)
+
+	keys := make([]string, 0, len(c.Extra))
+	for key := range c.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		set(key, c.Extra[key])
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// SpiceProxy creates a SPICE ticket for a QEMU VM. Proxy overrides the SPICE
+// proxy the viewer connects through, by default the node the request went to.
+func (s *QEMUService) SpiceProxy(ctx context.Context, node string, vmid int, proxy string) (*SpiceConfig, error) {
+	return s.client.spiceConfig(ctx, fmt.Sprintf("nodes/%s/qemu/%d/spiceproxy", node, vmid), proxy)
+}
+
+// spiceConfig posts to a SPICE endpoint (spiceproxy or spiceshell)
+func (c *Client) spiceConfig(ctx context.Context, path, proxy string) (*SpiceConfig, error) {
+	params := map[string]string{}
+	if proxy != "" {
+		params["proxy"] = proxy
+	}
+
+	req, err := c.NewRequest("POST", path, params, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *SpiceConfig
+	}
+	_, err = c.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
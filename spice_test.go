@@ -0,0 +1,164 @@
+package pve
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// spiceProxyPayload is a spiceproxy response. PVE escapes the newlines of the
+// CA certificate itself.
+const spiceProxyPayload = `{"data":{
+"type":"spice",
+"title":"VM 100 - web1",
+"host":"pvespiceproxy:6712f3a0:100:pve1::4e4b1c3dd8a5e0b4b1b0c6f1e4a1d2c3b4a5f6e7",
+"proxy":"http://192.168.1.10:3128",
+"tls-port":61000,
+"password":"44ad0c4b8c0e3e9a1f2b",
+"host-subject":"OU=PVE Cluster Node,O=Proxmox Virtual Environment,CN=pve1.example.com",
+"ca":"-----BEGIN CERTIFICATE-----\\nMIIFsDCCA5igAwIBAgIUTEST\\n-----END CERTIFICATE-----\\n",
+"delete-this-file":1,
+"toggle-fullscreen":"Shift+F11",
+"release-cursor":"Ctrl+Alt+R",
+"secure-attention":"Ctrl+Alt+Ins"
+}}`
+
+const spiceProxyVV = `[virt-viewer]
+type=spice
+title=VM 100 - web1
+host=pvespiceproxy:6712f3a0:100:pve1::4e4b1c3dd8a5e0b4b1b0c6f1e4a1d2c3b4a5f6e7
+proxy=http://192.168.1.10:3128
+tls-port=61000
+password=44ad0c4b8c0e3e9a1f2b
+host-subject=OU=PVE Cluster Node,O=Proxmox Virtual Environment,CN=pve1.example.com
+ca=-----BEGIN CERTIFICATE-----\nMIIFsDCCA5igAwIBAgIUTEST\n-----END CERTIFICATE-----\n
+delete-this-file=1
+toggle-fullscreen=Shift+F11
+release-cursor=Ctrl+Alt+R
+secure-attention=Ctrl+Alt+Ins
+`
+
+func TestSpiceProxy(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api2/json/nodes/pve1/qemu/100/spiceproxy" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+		w.Write([]byte(spiceProxyPayload))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, &AuthOptions{Username: "root@pam", TokenID: "test", TokenSecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := client.QEMU.SpiceProxy(context.Background(), "pve1", 100, "192.168.1.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "proxy=192.168.1.10" {
+		t.Errorf("query = %q, want %q", query, "proxy=192.168.1.10")
+	}
+
+	var b strings.Builder
+	n, err := config.WriteTo(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != spiceProxyVV {
+		t.Errorf("WriteTo() =\n%s\nwant\n%s", got, spiceProxyVV)
+	}
+	if n != int64(len(spiceProxyVV)) {
+		t.Errorf("WriteTo() = %d bytes, want %d", n, len(spiceProxyVV))
+	}
+}
+
+func TestSpiceConfigWriteTo(t *testing.T) {
+	tests := []struct {
+		name   string
+		config SpiceConfig
+		want   string
+	}{
+		{
+			name:   "empty",
+			config: SpiceConfig{},
+			want:   "[virt-viewer]\n",
+		},
+		{
+			name: "unescaped certificate",
+			config: SpiceConfig{
+				Type: "spice",
+				CA:   "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n",
+			},
+			want: "[virt-viewer]\ntype=spice\nca=-----BEGIN CERTIFICATE-----\\nMIIB\\n-----END CERTIFICATE-----\\n\n",
+		},
+		{
+			name: "extra settings sorted",
+			config: SpiceConfig{
+				Type:    "spice",
+				TLSPort: 61001,
+				Extra:   map[string]string{"zoom": "100", "enable-smartcard": "0", "empty": ""},
+			},
+			want: "[virt-viewer]\ntype=spice\ntls-port=61001\nenable-smartcard=0\nzoom=100\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if _, err := tt.config.WriteTo(&b); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("WriteTo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpiceConfigUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		port    int
+		del     bool
+		extra   map[string]string
+		wantErr bool
+	}{
+		{"number port", `{"tls-port":61000,"delete-this-file":1}`, 61000, true, nil, false},
+		{"string port", `{"tls-port":"61000","delete-this-file":"1"}`, 61000, true, nil, false},
+		{"keep file", `{"delete-this-file":0}`, 0, false, nil, false},
+		{"extra", `{"zoom":100}`, 0, false, map[string]string{"zoom": "100"}, false},
+		{"invalid port", `{"tls-port":"spice"}`, 0, false, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c SpiceConfig
+			err := c.UnmarshalJSON([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if c.TLSPort != tt.port || c.DeleteThisFile != tt.del {
+				t.Errorf("UnmarshalJSON() = port %d, delete %v, want %d, %v", c.TLSPort, c.DeleteThisFile, tt.port, tt.del)
+			}
+			if len(c.Extra) != len(tt.extra) {
+				t.Errorf("Extra = %v, want %v", c.Extra, tt.extra)
+			}
+			for k, v := range tt.extra {
+				if c.Extra[k] != v {
+					t.Errorf("Extra[%q] = %q, want %q", k, c.Extra[k], v)
+				}
+			}
+		})
+	}
+}
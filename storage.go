@@ -169,7 +169,9 @@ func (s *StorageService) GetDir(storageName string) ([]map[string]any, error) {
 	return result.Data, nil
 }
 
-// GetRRD retrieves storage RRD data
+// GetRRD retrieves storage RRD data.
+//
+// Deprecated: RRD data is kept per node, use RRDData.
 func (s *StorageService) GetRRD(storageName, timeframe string) (map[string]any, error) {
 	req, err := s.client.NewRequest("GET", fmt.Sprintf("storage/%s/rrddata", storageName), map[string]any{
 		"timeframe": timeframe,
@@ -0,0 +1,285 @@
+package pve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// tagUpdateRetries is the number of attempts for a tag update whose config digest went stale
+const tagUpdateRetries = 3
+
+// TagSet represents the tags of a guest. The API encodes tags as a
+// semicolon-separated string.
+type TagSet map[string]struct{}
+
+// NewTagSet creates a tag set from a list of tags
+func NewTagSet(tags ...string) TagSet {
+	t := TagSet{}
+	t.Add(tags...)
+	return t
+}
+
+// ParseTags parses a tag list separated by semicolons, commas or spaces
+func ParseTags(s string) TagSet {
+	return NewTagSet(strings.FieldsFunc(s, func(r rune) bool {
+		return r == ';' || r == ',' || r == ' '
+	})...)
+}
+
+// Has reports whether the set contains tag
+func (t TagSet) Has(tag string) bool {
+	_, ok := t[tag]
+	return ok
+}
+
+// Add adds tags to the set
+func (t *TagSet) Add(tags ...string) {
+	if *t == nil {
+		*t = TagSet{}
+	}
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			(*t)[tag] = struct{}{}
+		}
+	}
+}
+
+// Remove removes tags from the set
+func (t TagSet) Remove(tags ...string) {
+	for _, tag := range tags {
+		delete(t, tag)
+	}
+}
+
+// List returns the tags in alphabetical order
+func (t TagSet) List() []string {
+	tags := make([]string, 0, len(t))
+	for tag := range t {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	return tags
+}
+
+// String returns the tags in the semicolon-separated API format
+func (t TagSet) String() string {
+	return strings.Join(t.List(), ";")
+}
+
+// UnmarshalJSON decodes a semicolon-separated tag string
+func (t *TagSet) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t = ParseTags(s)
+
+	return nil
+}
+
+// MarshalJSON encodes the tags as a semicolon-separated string
+func (t TagSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// AddTags adds tags to a QEMU VM, keeping its existing tags
+func (s *QEMUService) AddTags(ctx context.Context, node string, vmid int, tags ...string) error {
+	return s.client.updateTags(ctx, node, "qemu", vmid, func(current TagSet) TagSet {
+		current.Add(tags...)
+		return current
+	})
+}
+
+// RemoveTags removes tags from a QEMU VM, keeping its other tags
+func (s *QEMUService) RemoveTags(ctx context.Context, node string, vmid int, tags ...string) error {
+	return s.client.updateTags(ctx, node, "qemu", vmid, func(current TagSet) TagSet {
+		current.Remove(tags...)
+		return current
+	})
+}
+
+// SetTags replaces all tags of a QEMU VM
+func (s *QEMUService) SetTags(ctx context.Context, node string, vmid int, tags ...string) error {
+	return s.client.updateTags(ctx, node, "qemu", vmid, func(TagSet) TagSet {
+		return NewTagSet(tags...)
+	})
+}
+
+// AddTags adds tags to an LXC container, keeping its existing tags
+func (s *LXCService) AddTags(ctx context.Context, node string, vmid int, tags ...string) error {
+	return s.client.updateTags(ctx, node, "lxc", vmid, func(current TagSet) TagSet {
+		current.Add(tags...)
+		return current
+	})
+}
+
+// RemoveTags removes tags from an LXC container, keeping its other tags
+func (s *LXCService) RemoveTags(ctx context.Context, node string, vmid int, tags ...string) error {
+	return s.client.updateTags(ctx, node, "lxc", vmid, func(current TagSet) TagSet {
+		current.Remove(tags...)
+		return current
+	})
+}
+
+// SetTags replaces all tags of an LXC container
+func (s *LXCService) SetTags(ctx context.Context, node string, vmid int, tags ...string) error {
+	return s.client.updateTags(ctx, node, "lxc", vmid, func(TagSet) TagSet {
+		return NewTagSet(tags...)
+	})
+}
+
+// updateTags applies update to the tags of a guest. The config digest is sent
+// along so that concurrent config changes are detected, in which case the
+// update is retried with the fresh configuration.
+func (c *Client) updateTags(ctx context.Context, node, vmType string, vmid int, update func(TagSet) TagSet) error {
+	path := fmt.Sprintf("nodes/%s/%s/%d/config", node, vmType, vmid)
+
+	var lastErr error
+	for attempt := 0; attempt < tagUpdateRetries; attempt++ {
+		req, err := c.NewRequest("GET", path, nil, WithContext(ctx))
+		if err != nil {
+			return err
+		}
+
+		var result struct {
+			Data struct {
+				Digest string `json:"digest"`
+				Tags   TagSet `json:"tags"`
+			} `json:"data"`
+		}
+		if _, err = c.Do(req, &result); err != nil {
+			return err
+		}
+
+		current := result.Data.Tags.String()
+		tags := update(NewTagSet(result.Data.Tags.List()...))
+		if tags.String() == current {
+			return nil
+		}
+
+		params := map[string]string{
+			"digest": result.Data.Digest,
+		}
+		if len(tags) > 0 {
+			params["tags"] = tags.String()
+		} else {
+			params["delete"] = "tags"
+		}
+
+		req, err = c.NewRequest("PUT", path, params, WithContext(ctx))
+		if err != nil {
+			return err
+		}
+
+		// A stale digest is reported as "detected modified configuration"
+		_, err = c.Do(req, nil)
+		if err == nil || !strings.Contains(err.Error(), "modified configuration") {
+			return err
+		}
+		lastErr = err
+	}
+
+	return lastErr
+}
+
+// TagStyle holds the tag style cluster options
+type TagStyle struct {
+	CaseSensitive int    `json:"case-sensitive"` // Check tags case-sensitive for uniqueness
+	ColorMap      string `json:"color-map"`      // <tag>:<hex-color>[:<hex-color-for-text>][;...]
+	Ordering      string `json:"ordering"`       // config or alphabetical
+	Shape         string `json:"shape"`          // full, circle, dense or none
+}
+
+// UserTagAccess holds the privilege options for user-settable tags
+type UserTagAccess struct {
+	UserAllow     string   `json:"user-allow"` // none, list, existing or free
+	UserAllowList []string `json:"user-allow-list"`
+}
+
+// TagOptions holds the tag related cluster options
+type TagOptions struct {
+	TagStyle       *TagStyle      `json:"tag-style"`
+	RegisteredTags []string       `json:"registered-tags"`
+	UserTagAccess  *UserTagAccess `json:"user-tag-access"`
+}
+
+// GetTagOptions retrieves the tag related cluster options
+func (s *ClusterService) GetTagOptions(ctx context.Context) (*TagOptions, error) {
+	req, err := s.client.NewRequest("GET", "cluster/options", nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *TagOptions
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// UpdateTagOptions updates the tag related cluster options. Only non-nil
+// fields are changed; empty values delete the option.
+func (s *ClusterService) UpdateTagOptions(ctx context.Context, options *TagOptions) error {
+	params := map[string]string{}
+	var deletes []string
+
+	set := func(key, value string) {
+		if value == "" {
+			deletes = append(deletes, key)
+		} else {
+			params[key] = value
+		}
+	}
+
+	if style := options.TagStyle; style != nil {
+		var parts []string
+		if style.CaseSensitive != 0 {
+			parts = append(parts, "case-sensitive=1")
+		}
+		if style.ColorMap != "" {
+			parts = append(parts, "color-map="+style.ColorMap)
+		}
+		if style.Ordering != "" {
+			parts = append(parts, "ordering="+style.Ordering)
+		}
+		if style.Shape != "" {
+			parts = append(parts, "shape="+style.Shape)
+		}
+		set("tag-style", strings.Join(parts, ","))
+	}
+
+	if options.RegisteredTags != nil {
+		set("registered-tags", strings.Join(options.RegisteredTags, ";"))
+	}
+
+	if access := options.UserTagAccess; access != nil {
+		var parts []string
+		if access.UserAllow != "" {
+			parts = append(parts, "user-allow="+access.UserAllow)
+		}
+		if len(access.UserAllowList) > 0 {
+			parts = append(parts, "user-allow-list="+strings.Join(access.UserAllowList, ";"))
+		}
+		set("user-tag-access", strings.Join(parts, ","))
+	}
+
+	if len(deletes) > 0 {
+		params["delete"] = strings.Join(deletes, ",")
+	}
+
+	req, err := s.client.NewRequest("PUT", "cluster/options", params, WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
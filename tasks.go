@@ -1,9 +1,64 @@
 package pve
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// taskPollInterval is the interval between task status checks in Wait
+const taskPollInterval = time.Second
+
+// taskLogLimit is the number of log lines read when a task log is inspected
+const taskLogLimit = 1000
+
+// UnmarshalJSON decodes a task object or a bare UPID string as returned
+// by endpoints that start a task. The deprecated UPType mirrors Type.
+func (t *Task) UnmarshalJSON(data []byte) error {
+	var upid string
+	if err := json.Unmarshal(data, &upid); err == nil {
+		*t = Task{}
+		return t.parseUPID(upid)
+	}
+
+	type task Task
+	var v task
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = Task(v)
+	t.UPType = t.Type
+
+	return nil
+}
+
+// parseUPID fills task fields from a UPID of the form
+// UPID:node:pid:pstart:starttime:type:id:user:
+func (t *Task) parseUPID(upid string) error {
+	parts := strings.Split(upid, ":")
+	if len(parts) < 8 || parts[0] != "UPID" {
+		return fmt.Errorf("invalid UPID: %s", upid)
+	}
+
+	t.UPID = upid
+	t.Node = parts[1]
+	if pid, err := strconv.ParseInt(parts[2], 16, 64); err == nil {
+		t.PID = int(pid)
+	}
+	if start, err := strconv.ParseInt(parts[4], 16, 64); err == nil {
+		t.StartTime = start
+	}
+	t.Type = parts[5]
+	t.UPType = t.Type
+	t.ID = parts[6]
+	t.User = parts[7]
+
+	return nil
+}
+
 // TasksService handles task-related API operations
 type TasksService struct {
 	client *Client
@@ -142,6 +197,35 @@ func (s *TasksService) GetNodeTaskLog(nodeName, upid string) ([]string, error) {
 	return result.Data, nil
 }
 
+// nodeTaskLog retrieves the log lines of a node task. The API returns the
+// lines as {n, t} objects.
+func (s *TasksService) nodeTaskLog(ctx context.Context, nodeName, upid string) ([]string, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/tasks/%s/log", nodeName, upid), map[string]any{
+		"limit": taskLogLimit,
+	}, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []struct {
+			N int    `json:"n"`
+			T string `json:"t"`
+		}
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, len(result.Data))
+	for i, line := range result.Data {
+		lines[i] = line.T
+	}
+
+	return lines, nil
+}
+
 // GetNodeTaskStatus retrieves task status for a node task
 func (s *TasksService) GetNodeTaskStatus(nodeName, upid string) (map[string]any, error) {
 	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/tasks/%s/status", nodeName, upid), nil)
@@ -179,3 +263,62 @@ func (s *TasksService) WaitForTask(upid string, timeout int) (map[string]any, er
 
 	return result.Data, nil
 }
+
+// Wait polls the status of a task until it has stopped.
+// It returns an error if the task did not finish with an OK exit status.
+func (s *TasksService) Wait(ctx context.Context, task *Task) (*Task, error) {
+	if task == nil || task.UPID == "" {
+		return nil, fmt.Errorf("task has no UPID")
+	}
+
+	ticker := time.NewTicker(taskPollInterval)
+	defer ticker.Stop()
+
+	for {
+		status, err := s.GetNodeTask(ctx, task.Node, task.UPID)
+		if err != nil {
+			return nil, err
+		}
+
+		if status.Status == "stopped" {
+			// Migrations move the guest, so its cached location is stale
+			if status.Type == "qmigrate" || status.Type == "vzmigrate" {
+				if vmid, err := strconv.Atoi(status.ID); err == nil {
+					s.client.VMs.InvalidateResource(vmid)
+				}
+			}
+			if status.ExitStatus != "OK" && !strings.HasPrefix(status.ExitStatus, "WARNINGS") {
+				return status, fmt.Errorf("task %s failed: %s", task.UPID, status.ExitStatus)
+			}
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetNodeTask retrieves the typed status of a node task
+func (s *TasksService) GetNodeTask(ctx context.Context, nodeName, upid string) (*Task, error) {
+	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/tasks/%s/status", nodeName, upid), nil, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *Task
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Data == nil {
+		return nil, fmt.Errorf("task %s not found", upid)
+	}
+
+	return result.Data, nil
+}
@@ -0,0 +1,194 @@
+package pve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ConvertToTemplate converts a QEMU VM into a template.
+// If disk is set (e.g. scsi0), only that disk is converted to a base image.
+func (s *QEMUService) ConvertToTemplate(ctx context.Context, node string, vmid int, disk string) (*Task, error) {
+	params := map[string]any{}
+	if disk != "" {
+		params["disk"] = disk
+	}
+
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/qemu/%d/template", node, vmid), params, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *Task
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// ConvertToTemplate converts an LXC container into a template
+func (s *LXCService) ConvertToTemplate(ctx context.Context, node string, vmid int) error {
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/lxc/%d/template", node, vmid), nil, WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
+
+// Templates retrieves all QEMU and LXC templates in the cluster
+func (s *ClusterService) Templates(ctx context.Context) (VMResources, error) {
+	resources, err := s.VMResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return resources.Templates(), nil
+}
+
+// NextID retrieves the next free VMID
+func (s *ClusterService) NextID(ctx context.Context) (int, error) {
+	req, err := s.client.NewRequest("GET", "cluster/nextid", nil, WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		Data json.Number `json:"data"`
+	}
+	_, err = s.client.Do(req, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(result.Data.String())
+}
+
+// GoldenImageOptions specifies how a golden image template is built
+type GoldenImageOptions struct {
+	Node            string        // Node of the source template
+	SourceID        int           // Template (or VM) to clone
+	NewID           int           // VMID of the new template (0 = next free ID)
+	Name            string        // Name of the new template
+	Full            bool          // Create a full instead of a linked clone
+	Tag             string        // Tag identifying the image lineage, moved to the new template
+	RetiredTag      string        // Tag added to previous templates of the lineage (optional)
+	DeletePrevious  bool          // Delete previous templates of the lineage instead of retagging them (requires Full)
+	ShutdownTimeout time.Duration // Time the clone gets to shut down after customization (default 3m)
+
+	// Customize is called with the running clone. If nil, the clone is
+	// converted to a template without being started.
+	Customize func(ctx context.Context, node string, vmid int) error
+}
+
+// GoldenImageResult describes a built golden image
+type GoldenImageResult struct {
+	VMID     int // VMID of the new template
+	Node     string
+	Previous []int // Previous templates of the lineage that were retagged or deleted
+}
+
+// BuildGoldenImage clones a template, lets Customize prepare the running clone,
+// shuts it down, converts it into a template and rotates the previous template
+// carrying opts.Tag. On error the result holds the VMID of the partially built
+// clone so it can be inspected or removed.
+func (s *QEMUService) BuildGoldenImage(ctx context.Context, opts *GoldenImageOptions) (*GoldenImageResult, error) {
+	if opts == nil || opts.Node == "" || opts.SourceID == 0 {
+		return nil, fmt.Errorf("golden image requires a source node and VMID")
+	}
+	// A linked clone keeps using the disks of its source, which is usually
+	// the previous template of the lineage
+	if opts.DeletePrevious && !opts.Full {
+		return nil, fmt.Errorf("deleting previous templates requires a full clone")
+	}
+
+	result := &GoldenImageResult{Node: opts.Node, VMID: opts.NewID}
+	if result.VMID == 0 {
+		id, err := s.client.Cluster.NextID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result.VMID = id
+	}
+
+	// Clone the source
+	task, err := s.Clone(opts.Node, opts.SourceID, result.VMID, opts.Name, opts.Full)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.client.Tasks.Wait(ctx, task); err != nil {
+		return result, fmt.Errorf("clone %d: %w", opts.SourceID, err)
+	}
+
+	// Customize the running clone
+	if opts.Customize != nil {
+		if _, err := s.client.ensureState(ctx, opts.Node, "qemu", result.VMID, PowerStateRunning); err != nil {
+			return result, err
+		}
+		if err := opts.Customize(ctx, opts.Node, result.VMID); err != nil {
+			return result, fmt.Errorf("customize %d: %w", result.VMID, err)
+		}
+		if _, err := s.PowerOff(ctx, opts.Node, result.VMID, &PowerOffOptions{Timeout: opts.ShutdownTimeout}); err != nil {
+			return result, err
+		}
+	}
+
+	// Convert to template
+	task, err = s.ConvertToTemplate(ctx, opts.Node, result.VMID, "")
+	if err != nil {
+		return result, err
+	}
+	if _, err := s.client.Tasks.Wait(ctx, task); err != nil {
+		return result, fmt.Errorf("convert %d to template: %w", result.VMID, err)
+	}
+
+	if opts.Tag == "" {
+		return result, nil
+	}
+
+	// Rotate the lineage tag
+	if err := s.AddTags(ctx, opts.Node, result.VMID, opts.Tag); err != nil {
+		return result, err
+	}
+
+	templates, err := s.client.Cluster.Templates(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, prev := range templates.OfType("qemu").WithTag(opts.Tag) {
+		if prev.VMID == result.VMID {
+			continue
+		}
+
+		if opts.DeletePrevious {
+			task, err := s.Delete(prev.Node, prev.VMID)
+			if err != nil {
+				return result, err
+			}
+			if _, err := s.client.Tasks.Wait(ctx, task); err != nil {
+				return result, fmt.Errorf("delete previous template %d: %w", prev.VMID, err)
+			}
+		} else {
+			if err := s.RemoveTags(ctx, prev.Node, prev.VMID, opts.Tag); err != nil {
+				return result, err
+			}
+			if opts.RetiredTag != "" {
+				if err := s.AddTags(ctx, prev.Node, prev.VMID, opts.RetiredTag); err != nil {
+					return result, err
+				}
+			}
+		}
+
+		result.Previous = append(result.Previous, prev.VMID)
+	}
+
+	return result, nil
+}
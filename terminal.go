@@ -0,0 +1,314 @@
+package pve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// terminalKeepalive is the interval between keepalive messages on a terminal
+const terminalKeepalive = 30 * time.Second
+
+// TermProxy holds the ticket of a termproxy or vncproxy session
+type TermProxy struct {
+	User     string `json:"user"`     // User or token that created the ticket
+	Ticket   string `json:"ticket"`   // VNC ticket for the websocket connection
+	Port     int    `json:"port"`     // Port the proxy listens on
+	UPID     string `json:"upid"`     // Proxy worker task
+	Cert     string `json:"cert"`     // Node certificate (vncproxy)
+	Password string `json:"password"` // Generated VNC password (vncproxy with generate-password)
+}
+
+// UnmarshalJSON decodes a proxy ticket whose port may be a number or a string
+func (p *TermProxy) UnmarshalJSON(data []byte) error {
+	type termProxy TermProxy
+	var v struct {
+		*termProxy
+		Port json.Number `json:"port"`
+	}
+	v.termProxy = (*termProxy)(p)
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	if v.Port != "" {
+		port, err := strconv.Atoi(v.Port.String())
+		if err != nil {
+			return fmt.Errorf("invalid proxy port %q: %w", v.Port, err)
+		}
+		p.Port = port
+	}
+
+	return nil
+}
+
+// TermProxy creates a terminal proxy for a node shell
+func (s *NodesService) TermProxy(ctx context.Context, name string) (*TermProxy, error) {
+	return s.client.termProxy(ctx, fmt.Sprintf("nodes/%s", name), nil)
+}
+
+// Shell opens an interactive login shell on a node
+func (s *NodesService) Shell(ctx context.Context, name string) (*Terminal, error) {
+	path := fmt.Sprintf("nodes/%s", name)
+
+	proxy, err := s.client.termProxy(ctx, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.OpenTerminal(ctx, path, proxy)
+}
+
+// TermProxy creates a terminal proxy for the console of an LXC container
+func (s *LXCService) TermProxy(ctx context.Context, node string, vmid int) (*TermProxy, error) {
+	return s.client.termProxy(ctx, fmt.Sprintf("nodes/%s/lxc/%d", node, vmid), nil)
+}
+
+// Console opens the console of an LXC container
+func (s *LXCService) Console(ctx context.Context, node string, vmid int) (*Terminal, error) {
+	path := fmt.Sprintf("nodes/%s/lxc/%d", node, vmid)
+
+	proxy, err := s.client.termProxy(ctx, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.OpenTerminal(ctx, path, proxy)
+}
+
+// SerialConsole opens serial port 0-3 of a QEMU VM. The VM needs a socket
+// serial device, e.g. serial0: socket.
+func (s *QEMUService) SerialConsole(ctx context.Context, node string, vmid int, port int) (*Terminal, error) {
+	if port < 0 || port > 3 {
+		return nil, fmt.Errorf("invalid serial port %d", port)
+	}
+
+	path := fmt.Sprintf("nodes/%s/qemu/%d", node, vmid)
+
+	proxy, err := s.client.termProxy(ctx, path, map[string]string{
+		"serial": fmt.Sprintf("serial%d", port),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.OpenTerminal(ctx, path, proxy)
+}
+
+// termProxy posts to the termproxy endpoint below path (nodes/{node}, nodes/{node}/lxc/{vmid}, ...)
+func (c *Client) termProxy(ctx context.Context, path string, params map[string]string) (*TermProxy, error) {
+	req, err := c.NewRequest("POST", path+"/termproxy", params, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *TermProxy
+	}
+	_, err = c.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Data == nil {
+		return nil, errors.New("no ticket returned by termproxy")
+	}
+
+	return result.Data, nil
+}
+
+// dialVNCWebsocket opens the vncwebsocket below path for a proxy ticket
+func (c *Client) dialVNCWebsocket(ctx context.Context, path string, proxy *TermProxy) (*websocket.Conn, error) {
+	if c.authCookie == "" && c.authToken == "" {
+		if err := c.authenticate(); err != nil {
+			return nil, err
+		}
+	}
+
+	u := *c.baseURL
+	u.Path += apiVersionPath + path + "/vncwebsocket"
+	u.RawQuery = url.Values{
+		"port":      {strconv.Itoa(proxy.Port)},
+		"vncticket": {proxy.Ticket},
+	}.Encode()
+
+	origin := *c.baseURL
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+
+	config, err := websocket.NewConfig(u.String(), origin.String())
+	if err != nil {
+		return nil, err
+	}
+	config.Protocol = []string{"binary"}
+	config.TlsConfig = c.client.GetTLSClientConfig().Clone()
+	if c.insecureTLS {
+		config.TlsConfig.InsecureSkipVerify = true
+	}
+	config.Header.Set("User-Agent", c.UserAgent)
+	if c.authCookie != "" {
+		config.Header.Set("Cookie", c.authCookie)
+	}
+	if c.authToken != "" {
+		config.Header.Set("Authorization", c.authToken)
+	}
+
+	ws, err := config.DialContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ws.PayloadType = websocket.BinaryFrame
+
+	return ws, nil
+}
+
+// Terminal is an interactive terminal session (node shell, container console
+// or serial console) over a termproxy websocket. Reads return the terminal
+// output, writes are sent as keyboard input.
+type Terminal struct {
+	ws      *websocket.Conn
+	mu      sync.Mutex // serializes messages
+	pending []byte     // output received along with the handshake reply
+	done    chan struct{}
+	once    sync.Once
+}
+
+// OpenTerminal connects to the termproxy ticket of the resource at path
+// (nodes/{node}, nodes/{node}/lxc/{vmid} or nodes/{node}/qemu/{vmid}) and
+// performs the ticket handshake.
+func (c *Client) OpenTerminal(ctx context.Context, path string, proxy *TermProxy) (*Terminal, error) {
+	ws, err := c.dialVNCWebsocket(ctx, path, proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authenticate the session with user:ticket, termproxy replies with OK
+	if _, err := ws.Write([]byte(proxy.User + ":" + proxy.Ticket + "\n")); err != nil {
+		ws.Close()
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		ws.SetReadDeadline(deadline)
+	}
+	var reply []byte
+	if err := websocket.Message.Receive(ws, &reply); err != nil {
+		ws.Close()
+		return nil, fmt.Errorf("terminal handshake: %w", err)
+	}
+	ws.SetReadDeadline(time.Time{})
+
+	pending, err := parseTermHandshake(reply)
+	if err != nil {
+		ws.Close()
+		return nil, err
+	}
+
+	t := &Terminal{
+		ws:      ws,
+		pending: pending,
+		done:    make(chan struct{}),
+	}
+	go t.keepalive()
+
+	return t, nil
+}
+
+// Read reads terminal output
+func (t *Terminal) Read(p []byte) (int, error) {
+	if len(t.pending) > 0 {
+		n := copy(p, t.pending)
+		t.pending = t.pending[n:]
+		return n, nil
+	}
+
+	return t.ws.Read(p)
+}
+
+// Write sends input to the terminal
+func (t *Terminal) Write(p []byte) (int, error) {
+	if err := t.send(termInputMessage(p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Resize sets the terminal size
+func (t *Terminal) Resize(cols, rows int) error {
+	return t.send(termResizeMessage(cols, rows))
+}
+
+// Close ends the terminal session
+func (t *Terminal) Close() error {
+	err := net.ErrClosed
+	t.once.Do(func() {
+		close(t.done)
+		err = t.ws.Close()
+	})
+
+	return err
+}
+
+// send writes a single protocol message
+func (t *Terminal) send(msg []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, err := t.ws.Write(msg)
+	return err
+}
+
+// termPingMessage keeps a termproxy session alive
+var termPingMessage = []byte("2")
+
+// termInputMessage frames keyboard input as 0:<byte length>:<data>
+func termInputMessage(p []byte) []byte {
+	msg := fmt.Appendf(nil, "0:%d:", len(p))
+	return append(msg, p...)
+}
+
+// termResizeMessage frames a terminal size change as 1:<cols>:<rows>:
+func termResizeMessage(cols, rows int) []byte {
+	return fmt.Appendf(nil, "1:%d:%d:", cols, rows)
+}
+
+// parseTermHandshake checks the reply to the ticket handshake and returns the
+// terminal output that arrived along with it
+func parseTermHandshake(reply []byte) ([]byte, error) {
+	if !bytes.HasPrefix(reply, []byte("OK")) {
+		return nil, fmt.Errorf("terminal handshake failed: %q", reply)
+	}
+
+	return reply[2:], nil
+}
+
+// keepalive pings the proxy until the terminal is closed
+func (t *Terminal) keepalive() {
+	ticker := time.NewTicker(terminalKeepalive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+			if err := t.send(termPingMessage); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package pve
+
+import "testing"
+
+func TestTermInputMessage(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "0:0:"},
+		{"ls\n", "0:3:ls\n"},
+		{"a:b:c", "0:5:a:b:c"},
+		{"ä€", "0:5:ä€"}, // length in bytes, not runes
+		{"\x03", "0:1:\x03"},
+	}
+
+	for _, tt := range tests {
+		if got := string(termInputMessage([]byte(tt.input))); got != tt.want {
+			t.Errorf("termInputMessage(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTermResizeMessage(t *testing.T) {
+	tests := []struct {
+		cols, rows int
+		want       string
+	}{
+		{80, 24, "1:80:24:"},
+		{200, 50, "1:200:50:"},
+	}
+
+	for _, tt := range tests {
+		if got := string(termResizeMessage(tt.cols, tt.rows)); got != tt.want {
+			t.Errorf("termResizeMessage(%d, %d) = %q, want %q", tt.cols, tt.rows, got, tt.want)
+		}
+	}
+}
+
+func TestParseTermHandshake(t *testing.T) {
+	tests := []struct {
+		reply   string
+		pending string
+		wantErr bool
+	}{
+		{"OK", "", false},
+		{"OKroot@pve1:~# ", "root@pve1:~# ", false},
+		{"permission denied", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		pending, err := parseTermHandshake([]byte(tt.reply))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTermHandshake(%q) error = %v, wantErr %v", tt.reply, err, tt.wantErr)
+			continue
+		}
+		if string(pending) != tt.pending {
+			t.Errorf("parseTermHandshake(%q) = %q, want %q", tt.reply, pending, tt.pending)
+		}
+	}
+}
@@ -18,12 +18,15 @@ type VM struct {
 	NetOut    int64    `json:"netout"`
 	Uptime    int      `json:"uptime"`
 	Template  int      `json:"template"`
+	Tags      TagSet   `json:"tags"`
 	PID       int      `json:"pid"`
 	Config    VMConfig `json:"-"`
 	VMData    any      `json:"data"`
 }
 
-// VMListOptions specifies VM listing options
+// VMListOptions specifies VM listing options.
+// Node and VMType are applied client-side; the other fields are not
+// supported by cluster/resources and are ignored.
 type VMListOptions struct {
 	Content string `url:"content,omitempty"`
 	VMType  string `url:"vmtype,omitempty"`
@@ -55,6 +58,9 @@ type VMConfig struct {
 	Description string            `json:"description"`
 	Cores       int               `json:"cores"`
 	Memory      int               `json:"memory"`
+	Agent       string            `json:"agent"`
+	Tags        TagSet            `json:"tags"`
+	Digest      string            `json:"digest"`
 	Storage     map[string]string `json:"storage"`
 }
 
@@ -86,37 +92,112 @@ type Cluster struct {
 	Version     string `json:"version"`
 	Quorate     int    `json:"quorate"`
 	Nodes       Nodes  `json:"nodes"`
+	// Deprecated: use Version, VersionInfo.Version mirrors it
 	VersionInfo struct {
 		Version string `json:"version"`
-	} `json:"version"`
+	} `json:"-"`
 }
 
 // ClusterResource represents a cluster resource
 type ClusterResource struct {
-	ID      string  `json:"id"`
-	Type    string  `json:"type"`
-	Content string  `json:"content"`
-	VMID    int     `json:"vmid"`
-	Name    string  `json:"name"`
-	Status  string  `json:"status"`
-	MaxMem  int64   `json:"maxmem"`
-	Mem     int64   `json:"mem"`
-	MaxDisk int64   `json:"maxdisk"`
-	Disk    int64   `json:"disk"`
-	CPU     float64 `json:"cpu"`
-	Uptime  int     `json:"uptime"`
-	Node    string  `json:"node"`
-	Plugin  string  `json:"plugin"`
-	Storage string  `json:"storage"`
-	Enable  int     `json:"enable"`
-	Shared  int     `json:"shared"`
-	Used    int64   `json:"used"`
-	Avail   int64   `json:"avail"`
+	ID         string  `json:"id"`
+	Type       string  `json:"type"`
+	Content    string  `json:"content"`
+	VMID       int     `json:"vmid"`
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	MaxMem     int64   `json:"maxmem"`
+	Mem        int64   `json:"mem"`
+	MaxDisk    int64   `json:"maxdisk"`
+	Disk       int64   `json:"disk"`
+	CPU        float64 `json:"cpu"`
+	MaxCPU     float64 `json:"maxcpu"`
+	Uptime     int     `json:"uptime"`
+	Node       string  `json:"node"`
+	Plugin     string  `json:"plugin"`
+	PluginType string  `json:"plugintype"`
+	Storage    string  `json:"storage"`
+	Enable     int     `json:"enable"`
+	Shared     int     `json:"shared"`
+	Used       int64   `json:"used"`
+	Avail      int64   `json:"avail"`
+	Pool       string  `json:"pool"`
+	Tags       TagSet  `json:"tags"`
+	Template   int     `json:"template"`
+	HAState    string  `json:"hastate"`
+	Lock       string  `json:"lock"`
+	Level      string  `json:"level"`
+	SDN        string  `json:"sdn"`
+	ZoneType   string  `json:"zone-type"`
 }
 
 // ClusterResources represents a list of cluster resources
 type ClusterResources []*ClusterResource
 
+// VMResource represents a QEMU VM or LXC container in the cluster resource list
+type VMResource struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"` // qemu or lxc
+	VMID      int     `json:"vmid"`
+	Name      string  `json:"name"`
+	Node      string  `json:"node"`
+	Status    string  `json:"status"`
+	Pool      string  `json:"pool"`
+	Tags      TagSet  `json:"tags"`
+	Template  int     `json:"template"`
+	HAState   string  `json:"hastate"`
+	Lock      string  `json:"lock"`
+	CPU       float64 `json:"cpu"`
+	MaxCPU    float64 `json:"maxcpu"`
+	Mem       int64   `json:"mem"`
+	MaxMem    int64   `json:"maxmem"`
+	Disk      int64   `json:"disk"`
+	MaxDisk   int64   `json:"maxdisk"`
+	DiskRead  int64   `json:"diskread"`
+	DiskWrite int64   `json:"diskwrite"`
+	NetIn     int64   `json:"netin"`
+	NetOut    int64   `json:"netout"`
+	Uptime    int     `json:"uptime"`
+}
+
+// StorageResource represents a storage on a node in the cluster resource list
+type StorageResource struct {
+	ID         string `json:"id"`
+	Storage    string `json:"storage"`
+	Node       string `json:"node"`
+	Status     string `json:"status"`
+	PluginType string `json:"plugintype"`
+	Content    string `json:"content"`
+	Shared     int    `json:"shared"`
+	Disk       int64  `json:"disk"`
+	MaxDisk    int64  `json:"maxdisk"`
+}
+
+// NodeResource represents a node in the cluster resource list
+type NodeResource struct {
+	ID         string  `json:"id"`
+	Node       string  `json:"node"`
+	Status     string  `json:"status"`
+	Level      string  `json:"level"`
+	CPU        float64 `json:"cpu"`
+	MaxCPU     float64 `json:"maxcpu"`
+	Mem        int64   `json:"mem"`
+	MaxMem     int64   `json:"maxmem"`
+	Disk       int64   `json:"disk"`
+	MaxDisk    int64   `json:"maxdisk"`
+	Uptime     int     `json:"uptime"`
+	CgroupMode int     `json:"cgroup-mode"`
+}
+
+// SDNResource represents an SDN zone in the cluster resource list
+type SDNResource struct {
+	ID       string `json:"id"`
+	SDN      string `json:"sdn"`
+	Node     string `json:"node"`
+	Status   string `json:"status"`
+	ZoneType string `json:"zone-type"`
+}
+
 // ClusterStatus represents cluster status information
 type ClusterStatus struct {
 	Type    string `json:"type"`
@@ -127,6 +208,9 @@ type ClusterStatus struct {
 	Level   string `json:"level"`
 	Quorate int    `json:"quorate"`
 	NodeID  int    `json:"nodeid"`
+	Local   int    `json:"local"`
+	Nodes   int    `json:"nodes"`   // Number of nodes including offline ones (cluster entry)
+	Version int    `json:"version"` // Corosync config version (cluster entry)
 }
 
 // Storage represents a storage entity
@@ -150,18 +234,19 @@ type Storages []*Storage
 
 // Task represents an async task
 type Task struct {
-	UPID      string `json:"upid"`
-	ID        string `json:"id"`
-	Node      string `json:"node"`
-	Type      string `json:"type"`
-	Status    string `json:"status"`
-	User      string `json:"user"`
-	TokenID   string `json:"tokenid"`
-	Saved     string `json:"saved"`
-	StartTime int64  `json:"starttime"`
-	EndTime   int64  `json:"endtime"`
-	UPType    string `json:"type"`
-	PID       int    `json:"pid"`
+	UPID       string `json:"upid"`
+	ID         string `json:"id"`
+	Node       string `json:"node"`
+	Type       string `json:"type"`
+	Status     string `json:"status"`
+	ExitStatus string `json:"exitstatus"`
+	User       string `json:"user"`
+	TokenID    string `json:"tokenid"`
+	Saved      string `json:"saved"`
+	StartTime  int64  `json:"starttime"`
+	EndTime    int64  `json:"endtime"`
+	UPType     string `json:"-"` // Deprecated: use Type, UPType mirrors it
+	PID        int    `json:"pid"`
 }
 
 // Tasks represents a list of tasks
@@ -215,6 +300,7 @@ type VMStatus struct {
 	Config     string `json:"config"`
 	CPUs       int    `json:"cpus"`
 	QMPStatus  string `json:"qmpstatus"`
+	Lock       string `json:"lock"`
 	Monitor    int    `json:"monitor"`
 	Spice      int    `json:"spice"`
 	SnapshotVM string `json:"snapshot"`
@@ -235,6 +321,14 @@ type VMSnapshot struct {
 // VMSnapshots represents a list of VM snapshots
 type VMSnapshots []*VMSnapshot
 
+// ConsistentSnapshotResult describes a snapshot taken with frozen guest filesystems
+type ConsistentSnapshotResult struct {
+	Task        *Task    // Finished snapshot task
+	Filesystems []string // Mountpoints frozen by the snapshot task
+	Frozen      int      // Number of filesystems frozen by the snapshot task
+	Thawed      int      // Filesystems left frozen after the task and thawed by ConsistentSnapshot (normally 0)
+}
+
 // GuestAgent represents guest agent information
 type GuestAgent struct {
 	Info struct {
@@ -286,24 +380,185 @@ type GuestExecResult struct {
 	ExitCode int    `json:"exit-code"`
 }
 
+// GuestOSInfo represents guest operating system information
+type GuestOSInfo struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	PrettyName    string `json:"pretty-name"`
+	Version       string `json:"version"`
+	VersionID     string `json:"version-id"`
+	Variant       string `json:"variant"`
+	VariantID     string `json:"variant-id"`
+	KernelRelease string `json:"kernel-release"`
+	KernelVersion string `json:"kernel-version"`
+	Machine       string `json:"machine"`
+}
+
+// GuestTimezone represents the guest timezone
+type GuestTimezone struct {
+	Zone   string `json:"zone"`
+	Offset int    `json:"offset"` // Offset to UTC in seconds
+}
+
+// GuestUser represents a user logged in to the guest
+type GuestUser struct {
+	User      string  `json:"user"`
+	Domain    string  `json:"domain"`
+	LoginTime float64 `json:"login-time"`
+}
+
+// GuestVCPU represents a guest virtual CPU
+type GuestVCPU struct {
+	LogicalID  int  `json:"logical-id"`
+	Online     bool `json:"online"`
+	CanOffline bool `json:"can-offline"`
+}
+
+// GuestMemoryBlock represents a guest memory block
+type GuestMemoryBlock struct {
+	PhysIndex  uint64 `json:"phys-index"`
+	Online     bool   `json:"online"`
+	CanOffline bool   `json:"can-offline"`
+}
+
+// GuestFSTrimResult represents the fstrim result for a single path
+type GuestFSTrimResult struct {
+	Path    string `json:"path"`
+	Trimmed int64  `json:"trimmed"`
+	Minimum int64  `json:"minimum"`
+	Error   string `json:"error"`
+}
+
 // NodeInfo represents detailed node information
 type NodeInfo struct {
-	Node          string         `json:"node"`
-	Cpuinfo       map[string]any `json:"cpuinfo"`
+	Node       string      `json:"node"`
+	CPUInfo    NodeCPUInfo `json:"cpuinfo"`
+	Kversion   string      `json:"kversion"`
+	PVEVersion string      `json:"pveversion"`
+	MaxCPU     int         `json:"maxcpu"`
+	MaxDisk    int64       `json:"maxdisk"`
+	MaxMem     int64       `json:"maxmem"`
+	Uptime     int64       `json:"uptime"`
+}
+
+// NodeStatus represents the status of a node
+type NodeStatus struct {
+	CPU           float64        `json:"cpu"`  // CPU usage (0-1)
+	Wait          float64        `json:"wait"` // IO wait (0-1)
+	Idle          float64        `json:"idle"`
+	LoadAvg       LoadAvg        `json:"loadavg"`
+	Uptime        int64          `json:"uptime"`
 	Kversion      string         `json:"kversion"`
-	MaxDisk       int64          `json:"maxdisk"`
-	MaxMem        int64          `json:"maxmem"`
-	SharedMaxDisk int64          `json:"shared_maxdisk"`
+	PVEVersion    string         `json:"pveversion"`
+	CPUInfo       NodeCPUInfo    `json:"cpuinfo"`
+	Memory        NodeMemory     `json:"memory"`
+	Swap          NodeMemory     `json:"swap"`
+	RootFS        NodeFilesystem `json:"rootfs"`
+	KSM           NodeKSM        `json:"ksm"`
+	BootInfo      NodeBootInfo   `json:"boot-info"`
+	CurrentKernel NodeKernelInfo `json:"current-kernel"`
+}
+
+// LoadAvg holds the 1, 5 and 15 minute load averages
+type LoadAvg [3]float64
+
+// NodeCPUInfo describes the CPU of a node
+type NodeCPUInfo struct {
+	Model   string `json:"model"`
+	Sockets int    `json:"sockets"`
+	Cores   int    `json:"cores"` // Physical cores
+	CPUs    int    `json:"cpus"`  // Logical threads
+	MHz     string `json:"mhz"`
+	HVM     string `json:"hvm"` // "1" if hardware virtualization is supported
+	Flags   string `json:"flags"`
+	UserHz  int    `json:"user_hz"`
+}
+
+// NodeMemory represents memory or swap usage in bytes
+type NodeMemory struct {
+	Total     int64 `json:"total"`
+	Used      int64 `json:"used"`
+	Free      int64 `json:"free"`
+	Available int64 `json:"available"` // Memory only
+}
+
+// NodeFilesystem represents filesystem usage in bytes
+type NodeFilesystem struct {
+	Total int64 `json:"total"`
+	Used  int64 `json:"used"`
+	Free  int64 `json:"free"`
+	Avail int64 `json:"avail"`
+}
+
+// NodeKSM represents kernel samepage merging statistics
+type NodeKSM struct {
+	Shared int64 `json:"shared"` // Bytes shared by KSM
+}
+
+// NodeBootInfo describes how a node was booted
+type NodeBootInfo struct {
+	Mode       string `json:"mode"`       // efi or legacy-bios
+	SecureBoot int    `json:"secureboot"` // 1 if booted with secure boot (efi only)
+}
+
+// NodeKernelInfo describes the running kernel of a node
+type NodeKernelInfo struct {
+	Sysname string `json:"sysname"`
+	Release string `json:"release"`
+	Version string `json:"version"`
+	Machine string `json:"machine"`
 }
 
 // MigrateOptions specifies VM migration options
 type MigrateOptions struct {
-	Online           bool   // Online migration
-	Force            bool   // Force migration
-	MigrationNetwork string // Migration network
-	BWLimit          int    // Bandwidth limit (KB/s)
-	TargetStorage    string // Target storage
-	Delete           bool   // Delete source data
+	Online             bool              // Online migration
+	Force              bool              // Force migration of VMs with local devices (QEMU)
+	MigrationNetwork   string            // Migration network (QEMU)
+	MigrationType      string            // Migration traffic: secure or insecure (QEMU)
+	BWLimit            int               // Bandwidth limit (KB/s)
+	TargetStorage      string            // Target storage for all disks, or "1" to keep storage IDs
+	TargetStorages     map[string]string // Source to target storage mapping
+	WithLocalDisks     bool              // Live migrate local disks (QEMU)
+	WithConntrackState bool              // Migrate conntrack entries of running VMs (QEMU)
+	Restart            bool              // Restart migration (LXC)
+	Timeout            int               // Shutdown timeout in seconds for restart migration (LXC)
+	TargetBridge       string            // Target bridge for all NICs, or "1" to keep bridge IDs (remote)
+	TargetBridges      map[string]string // Source to target bridge mapping (remote)
+	TargetVMID         int               // VMID on the target cluster, defaults to the source VMID (remote)
+	Delete             bool              // Delete source after a remote migration
+}
+
+// MigratePreconditions describes whether and where a guest can be migrated
+type MigratePreconditions struct {
+	Running              int                            `json:"running"`
+	AllowedNodes         []string                       `json:"allowed_nodes"`
+	NotAllowedNodes      map[string]*MigrateNodeBlocker `json:"not_allowed_nodes"`
+	LocalDisks           []MigrateLocalDisk             `json:"local_disks"`
+	LocalResources       []string                       `json:"local_resources"`
+	MappedResources      []string                       `json:"mapped-resources"`
+	DependentHAResources []string                       `json:"dependent-ha-resources"`
+	HasDBusVMState       int                            `json:"has-dbus-vmstate"`
+}
+
+// MigrateNodeBlocker lists the reasons a node is not allowed as migration target
+type MigrateNodeBlocker struct {
+	UnavailableStorages  []string                    `json:"unavailable_storages"`
+	UnavailableResources []string                    `json:"unavailable-resources"`
+	BlockingHAResources  []MigrateBlockingHAResource `json:"blocking-ha-resources"`
+}
+
+// MigrateBlockingHAResource represents an HA resource blocking a migration
+type MigrateBlockingHAResource struct {
+	SID   string `json:"sid"`
+	Cause string `json:"cause"`
+}
+
+// MigrateLocalDisk represents a local disk of a VM that affects migration
+type MigrateLocalDisk struct {
+	VolID    string `json:"volid"`
+	Size     int64  `json:"size"`
+	CDROM    int    `json:"cdrom"`
+	IsUnused int    `json:"is_unused"`
 }
 
 // NetworkInterface represents a VM network interface
@@ -21,7 +21,9 @@ func (s *VersionService) Get() (*Version, error) {
 	return version, nil
 }
 
-// GetAPT retrieves APT version information
+// GetAPT retrieves APT version information.
+//
+// Deprecated: apt/update only exists per node, use NodesService.ListUpdates.
 func (s *VersionService) GetAPT() (map[string]any, error) {
 	req, err := s.client.NewRequest("GET", "apt/update", nil)
 	if err != nil {
@@ -39,7 +41,9 @@ func (s *VersionService) GetAPT() (map[string]any, error) {
 	return result.Data, nil
 }
 
-// GetPackages retrieves available packages
+// GetPackages retrieves available packages.
+//
+// Deprecated: apt/versions only exists per node, use NodesService.PackageVersions.
 func (s *VersionService) GetPackages() ([]map[string]any, error) {
 	req, err := s.client.NewRequest("GET", "apt/versions", nil)
 	if err != nil {
@@ -57,7 +61,9 @@ func (s *VersionService) GetPackages() ([]map[string]any, error) {
 	return result.Data, nil
 }
 
-// Changelog retrieves changelog information for a package
+// Changelog retrieves changelog information for a package.
+//
+// Deprecated: apt/changelog only exists per node, use NodesService.PackageChangelog.
 func (s *VersionService) Changelog(packageName string) (string, error) {
 	req, err := s.client.NewRequest("GET", "apt/changelog", map[string]any{
 		"package": packageName,
@@ -1,17 +1,34 @@
 package pve
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imroc/req/v3"
 )
 
 // VMsService handles VM-related API operations
 type VMsService struct {
 	client *Client
+
+	// Cached vmid -> resource mapping used to locate guests
+	mu         sync.Mutex
+	resources  map[int]*VM
+	fetched    time.Time
+	ttl        time.Duration
+	refresh    *resourceRefresh // running refresh, nil if none
+	generation int              // incremented by InvalidateResource
 }
 
 // List retrieves all VMs
 func (s *VMsService) List(options *VMListOptions) ([]*VM, error) {
-	req, err := s.client.NewRequest("GET", "cluster/resources", options)
+	req, err := s.client.NewRequest("GET", "cluster/resources", map[string]string{
+		"type": "vm",
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -24,12 +41,19 @@ func (s *VMsService) List(options *VMListOptions) ([]*VM, error) {
 		return nil, err
 	}
 
-	// Filter only VMs/LXCs
+	// Filter only VMs/LXCs matching the options
 	var vms []*VM
 	for _, vm := range result.Data {
-		if vm.Type == "qemu" || vm.Type == "lxc" {
-			vms = append(vms, vm)
+		if vm.Type != "qemu" && vm.Type != "lxc" {
+			continue
+		}
+		if options != nil && options.Node != "" && vm.Node != options.Node {
+			continue
+		}
+		if options != nil && options.VMType != "" && vm.Type != options.VMType {
+			continue
 		}
+		vms = append(vms, vm)
 	}
 
 	return vms, nil
@@ -63,41 +87,185 @@ func (s *VMsService) Get(vmid int) (*VM, error) {
 	return vm, nil
 }
 
-// GetVMResource retrieves VM resource information
+// GetVMResource retrieves VM resource information.
+// Node and type are resolved from a cached cluster-wide resource list that is
+// refreshed after the resolver TTL, on a cache miss, after a migration task
+// finished in Tasks.Wait or when a call found the guest gone from its node.
 func (s *VMsService) GetVMResource(vmid int) (*VM, error) {
-	req, err := s.client.NewRequest("GET", fmt.Sprintf("cluster/resources/vm/%d", vmid), nil)
+	return s.ResolveVM(context.Background(), vmid)
+}
+
+// ResolveVM is GetVMResource with a context for the resource list refresh.
+// Concurrent lookups share a single refresh and do not block cache hits.
+func (s *VMsService) ResolveVM(ctx context.Context, vmid int) (*VM, error) {
+	s.mu.Lock()
+	vm, ok := s.resources[vmid]
+	fresh := time.Since(s.fetched) < s.ttl
+	s.mu.Unlock()
+
+	if !ok || !fresh {
+		if err := s.refreshResources(ctx); err != nil {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		vm, ok = s.resources[vmid]
+		s.mu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("VM %d not found", vmid)
+		}
+	}
+
+	// Return a copy so callers cannot modify the cache
+	resource := *vm
+	return &resource, nil
+}
+
+// InvalidateResource drops the cached location of a VM, or of all VMs if vmid is 0
+func (s *VMsService) InvalidateResource(vmid int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generation++
+	if vmid == 0 {
+		s.resources = nil
+		return
+	}
+	delete(s.resources, vmid)
+}
+
+// resourceRefresh is a cluster/resources request shared by concurrent lookups
+type resourceRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// refreshResources reloads all guests from cluster/resources. A refresh that
+// is already running is joined instead of starting another one.
+func (s *VMsService) refreshResources(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		if r := s.refresh; r != nil {
+			s.mu.Unlock()
+
+			select {
+			case <-r.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			// The refresh was cancelled by its own caller, try again with ours
+			if errors.Is(r.err, context.Canceled) || errors.Is(r.err, context.DeadlineExceeded) {
+				continue
+			}
+			return r.err
+		}
+
+		r := &resourceRefresh{done: make(chan struct{})}
+		s.refresh = r
+		generation := s.generation
+		s.mu.Unlock()
+
+		resources, err := s.fetchResources(ctx)
+
+		s.mu.Lock()
+		if err == nil {
+			s.resources = resources
+			s.fetched = time.Now()
+			// Invalidated while fetching, the list may predate the change
+			if s.generation != generation {
+				s.fetched = time.Time{}
+			}
+		}
+		s.refresh = nil
+		r.err = err
+		close(r.done)
+		s.mu.Unlock()
+
+		return err
+	}
+}
+
+// fetchResources lists all guests from cluster/resources by vmid
+func (s *VMsService) fetchResources(ctx context.Context) (map[int]*VM, error) {
+	req, err := s.client.NewRequest("GET", "cluster/resources", map[string]string{
+		"type": "vm",
+	}, WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 
 	var result struct {
-		Data *VM
+		Data []*VM
 	}
 	_, err = s.client.Do(req, &result)
 	if err != nil {
 		return nil, err
 	}
 
-	return result.Data, nil
+	resources := make(map[int]*VM, len(result.Data))
+	for _, vm := range result.Data {
+		if vm.Type == "qemu" || vm.Type == "lxc" {
+			resources[vm.ID] = vm
+		}
+	}
+
+	return resources, nil
 }
 
-// GetStatus retrieves VM status information
-func (s *VMsService) GetStatus(vmid int) (*VMStatus, error) {
-	// First get the VM resource to find the node
-	vm, err := s.GetVMResource(vmid)
+// withVM calls fn with the cached location of a guest. If fn fails because the
+// guest's configuration does not exist on that node, the guest was moved since
+// the lookup; its cache entry is dropped and fn is called once more with the
+// fresh location.
+func (s *VMsService) withVM(ctx context.Context, vmid int, fn func(vm *VM) error) error {
+	vm, err := s.ResolveVM(ctx, vmid)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	err = fn(vm)
+	if err == nil || !isGuestMoved(err) {
+		return err
 	}
 
-	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/%s/%d/status/current", vm.Node, vm.Type, vmid), nil)
+	s.InvalidateResource(vmid)
+	vm, err = s.ResolveVM(ctx, vmid)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
+	return fn(vm)
+}
+
+// do sends the request built for the location of a guest, see withVM
+func (s *VMsService) do(vmid int, build func(vm *VM) (*req.Request, error), v any) error {
+	return s.withVM(context.Background(), vmid, func(vm *VM) error {
+		r, err := build(vm)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.client.Do(r, v)
+		return err
+	})
+}
+
+// isGuestMoved reports whether err is PVE's "Configuration file
+// 'nodes/{node}/qemu-server/{vmid}.conf' does not exist" for a guest that is
+// not (or no longer) on the requested node
+func isGuestMoved(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "Configuration file 'nodes/") && strings.Contains(msg, "does not exist")
+}
+
+// GetStatus retrieves VM status information
+func (s *VMsService) GetStatus(vmid int) (*VMStatus, error) {
+	// First get the VM resource to find the node
 	var result struct {
 		Data *VMStatus
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/%s/%d/status/current", vm.Node, vm.Type, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -107,20 +275,12 @@ func (s *VMsService) GetStatus(vmid int) (*VMStatus, error) {
 
 // StartVM starts a VM
 func (s *VMsService) Start(vmid int) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/status/start", vm.Node, vm.Type, vmid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/status/start", vm.Node, vm.Type, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -130,20 +290,12 @@ func (s *VMsService) Start(vmid int) (*Task, error) {
 
 // StopVM stops a VM
 func (s *VMsService) Stop(vmid int) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/status/stop", vm.Node, vm.Type, vmid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/status/stop", vm.Node, vm.Type, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -153,20 +305,12 @@ func (s *VMsService) Stop(vmid int) (*Task, error) {
 
 // ShutdownVM shuts down a VM gracefully
 func (s *VMsService) Shutdown(vmid int) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/status/shutdown", vm.Node, vm.Type, vmid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/status/shutdown", vm.Node, vm.Type, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -176,20 +320,12 @@ func (s *VMsService) Shutdown(vmid int) (*Task, error) {
 
 // RebootVM reboots a VM
 func (s *VMsService) Reboot(vmid int) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/status/reboot", vm.Node, vm.Type, vmid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/status/reboot", vm.Node, vm.Type, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -199,20 +335,12 @@ func (s *VMsService) Reboot(vmid int) (*Task, error) {
 
 // SuspendVM suspends a VM
 func (s *VMsService) Suspend(vmid int) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/status/suspend", vm.Node, vm.Type, vmid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/status/suspend", vm.Node, vm.Type, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -222,20 +350,12 @@ func (s *VMsService) Suspend(vmid int) (*Task, error) {
 
 // ResumeVM resumes a suspended VM
 func (s *VMsService) Resume(vmid int) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/status/resume", vm.Node, vm.Type, vmid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/status/resume", vm.Node, vm.Type, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -245,43 +365,29 @@ func (s *VMsService) Resume(vmid int) (*Task, error) {
 
 // DeleteVM removes a VM
 func (s *VMsService) Delete(vmid int) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("DELETE", fmt.Sprintf("nodes/%s/%s/%d", vm.Node, vm.Type, vmid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("DELETE", fmt.Sprintf("nodes/%s/%s/%d", vm.Node, vm.Type, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
 
+	s.InvalidateResource(vmid)
+
 	return result.Data, nil
 }
 
 // GetConfig retrieves VM configuration
 func (s *VMsService) GetConfig(vmid int) (*VMConfig, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/%s/%d/config", vm.Node, vm.Type, vmid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *VMConfig
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/%s/%d/config", vm.Node, vm.Type, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -291,26 +397,18 @@ func (s *VMsService) GetConfig(vmid int) (*VMConfig, error) {
 
 // UpdateConfig updates VM configuration
 func (s *VMsService) UpdateConfig(vmid int, config map[string]string) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
 	// Convert config map to URL values
 	values := make(map[string]string)
 	for k, v := range config {
 		values[k] = v
 	}
 
-	req, err := s.client.NewRequest("PUT", fmt.Sprintf("nodes/%s/%s/%d/config", vm.Node, vm.Type, vmid), values)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("PUT", fmt.Sprintf("nodes/%s/%s/%d/config", vm.Node, vm.Type, vmid), values)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -320,20 +418,12 @@ func (s *VMsService) UpdateConfig(vmid int, config map[string]string) (*Task, er
 
 // ListSnapshots lists VM snapshots
 func (s *VMsService) ListSnapshots(vmid int) ([]*VMSnapshot, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/%s/%d/snapshot", vm.Node, vm.Type, vmid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data []*VMSnapshot
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/%s/%d/snapshot", vm.Node, vm.Type, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -343,23 +433,15 @@ func (s *VMsService) ListSnapshots(vmid int) ([]*VMSnapshot, error) {
 
 // CreateSnapshot creates a VM snapshot
 func (s *VMsService) CreateSnapshot(vmid int, name, description string) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/snapshot", vm.Node, vm.Type, vmid), map[string]string{
-		"snapname":    name,
-		"description": description,
-	})
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/snapshot", vm.Node, vm.Type, vmid), map[string]string{
+			"snapname":    name,
+			"description": description,
+		})
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -369,20 +451,12 @@ func (s *VMsService) CreateSnapshot(vmid int, name, description string) (*Task,
 
 // DeleteSnapshot deletes a VM snapshot
 func (s *VMsService) DeleteSnapshot(vmid int, snapshotName string) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("DELETE", fmt.Sprintf("nodes/%s/%s/%d/snapshot/%s", vm.Node, vm.Type, vmid, snapshotName), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("DELETE", fmt.Sprintf("nodes/%s/%s/%d/snapshot/%s", vm.Node, vm.Type, vmid, snapshotName), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -392,20 +466,12 @@ func (s *VMsService) DeleteSnapshot(vmid int, snapshotName string) (*Task, error
 
 // RollbackSnapshot rolls back to a VM snapshot
 func (s *VMsService) RollbackSnapshot(vmid int, snapshotName string) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/snapshot/%s/rollback", vm.Node, vm.Type, vmid, snapshotName), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/snapshot/%s/rollback", vm.Node, vm.Type, vmid, snapshotName), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -415,25 +481,17 @@ func (s *VMsService) RollbackSnapshot(vmid int, snapshotName string) (*Task, err
 
 // CloneVM clones a VM
 func (s *VMsService) Clone(vmid int, newID int, name string) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/clone", vm.Node, vm.Type, vmid), map[string]any{
-		"vmid":   newID,
-		"name":   name,
-		"full":   1,
-		"target": vm.Node,
-	})
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/clone", vm.Node, vm.Type, vmid), map[string]any{
+			"vmid":   newID,
+			"name":   name,
+			"full":   1,
+			"target": vm.Node,
+		})
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -443,20 +501,12 @@ func (s *VMsService) Clone(vmid int, newID int, name string) (*Task, error) {
 
 // GetVNCInfo retrieves VNC console information
 func (s *VMsService) GetVNCInfo(vmid int) (map[string]any, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/%s/%d/vncproxy", vm.Node, vm.Type, vmid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data map[string]any
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/%s/%d/vncproxy", vm.Node, vm.Type, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -466,20 +516,12 @@ func (s *VMsService) GetVNCInfo(vmid int) (map[string]any, error) {
 
 // GetGuestAgentInfo retrieves guest agent information
 func (s *VMsService) GetGuestAgentInfo(vmid int) (*GuestAgent, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/get-guest-info", vm.Node, vmid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *GuestAgent
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/get-guest-info", vm.Node, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -489,22 +531,14 @@ func (s *VMsService) GetGuestAgentInfo(vmid int) (*GuestAgent, error) {
 
 // ExecGuestCommand executes a command in the guest
 func (s *VMsService) ExecGuestCommand(vmid int, command string) (*GuestExec, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/qemu/%d/agent/exec", vm.Node, vmid), map[string]string{
-		"command": command,
-	})
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *GuestExec
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/qemu/%d/agent/exec", vm.Node, vmid), map[string]string{
+			"command": command,
+		})
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -514,20 +548,12 @@ func (s *VMsService) ExecGuestCommand(vmid int, command string) (*GuestExec, err
 
 // GetExecOutput retrieves output from a guest command execution
 func (s *VMsService) GetExecOutput(vmid int, pid int) (*GuestExecResult, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/exec/%d", vm.Node, vmid, pid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *GuestExecResult
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/exec/%d", vm.Node, vmid, pid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -537,24 +563,16 @@ func (s *VMsService) GetExecOutput(vmid int, pid int) (*GuestExecResult, error)
 
 // Reset hard resets a QEMU VM
 func (s *VMsService) Reset(vmid int) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	if vm.Type != "qemu" {
-		return nil, fmt.Errorf("reset is only supported for QEMU VMs")
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/qemu/%d/status/reset", vm.Node, vmid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		if vm.Type != "qemu" {
+			return nil, fmt.Errorf("reset is only supported for QEMU VMs")
+		}
+
+		return s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/qemu/%d/status/reset", vm.Node, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -564,23 +582,15 @@ func (s *VMsService) Reset(vmid int) (*Task, error) {
 
 // ResizeDisk resizes a VM disk
 func (s *VMsService) ResizeDisk(vmid int, disk string, size string) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := s.client.NewRequest("PUT", fmt.Sprintf("nodes/%s/%s/%d/resize", vm.Node, vm.Type, vmid), map[string]string{
-		"disk": disk,
-		"size": size,
-	})
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		return s.client.NewRequest("PUT", fmt.Sprintf("nodes/%s/%s/%d/resize", vm.Node, vm.Type, vmid), map[string]string{
+			"disk": disk,
+			"size": size,
+		})
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -590,118 +600,86 @@ func (s *VMsService) ResizeDisk(vmid int, disk string, size string) (*Task, erro
 
 // Migrate migrates a VM to another node
 func (s *VMsService) Migrate(vmid int, target string, options *MigrateOptions) (*Task, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	params := map[string]any{
-		"target": target,
-	}
-
-	if options != nil {
-		if options.Online {
-			params["online"] = 1
-		}
-		if options.Force {
-			params["force"] = 1
-		}
-		if options.MigrationNetwork != "" {
-			params["migration_network"] = options.MigrationNetwork
-		}
-		if options.BWLimit > 0 {
-			params["bwlimit"] = options.BWLimit
-		}
-		if options.TargetStorage != "" {
-			params["targetstorage"] = options.TargetStorage
-		}
-		if options.Delete {
-			params["delete"] = 1
-		}
-	}
-
-	req, err := s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/migrate", vm.Node, vm.Type, vmid), params)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data *Task
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		params := migrateParams(vm.Type, target, options)
+
+		return s.client.NewRequest("POST", fmt.Sprintf("nodes/%s/%s/%d/migrate", vm.Node, vm.Type, vmid), params)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
 
+	s.InvalidateResource(vmid)
+
 	return result.Data, nil
 }
 
 // GetNetworkInterfaces retrieves VM network interfaces (QEMU with guest agent)
 func (s *VMsService) GetNetworkInterfaces(vmid int) ([]NetworkInterface, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	if vm.Type == "qemu" {
-		req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/network-get-interfaces", vm.Node, vmid), nil)
-		if err != nil {
-			return nil, err
+	var interfaces []NetworkInterface
+	err := s.withVM(context.Background(), vmid, func(vm *VM) error {
+		if vm.Type == "qemu" {
+			req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/network-get-interfaces", vm.Node, vmid), nil)
+			if err != nil {
+				return err
+			}
+
+			var result struct {
+				Data struct {
+					Result []NetworkInterface `json:"result"`
+				} `json:"data"`
+			}
+			_, err = s.client.Do(req, &result)
+			if err != nil {
+				return err
+			}
+
+			interfaces = result.Data.Result
+			return nil
+		} else if vm.Type == "lxc" {
+			req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/lxc/%d/interfaces", vm.Node, vmid), nil)
+			if err != nil {
+				return err
+			}
+
+			var result struct {
+				Data []NetworkInterface `json:"data"`
+			}
+			_, err = s.client.Do(req, &result)
+			if err != nil {
+				return err
+			}
+
+			interfaces = result.Data
+			return nil
 		}
 
-		var result struct {
-			Data struct {
-				Result []NetworkInterface `json:"result"`
-			} `json:"data"`
-		}
-		_, err = s.client.Do(req, &result)
-		if err != nil {
-			return nil, err
-		}
-
-		return result.Data.Result, nil
-	} else if vm.Type == "lxc" {
-		req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/lxc/%d/interfaces", vm.Node, vmid), nil)
-		if err != nil {
-			return nil, err
-		}
-
-		var result struct {
-			Data []NetworkInterface `json:"data"`
-		}
-		_, err = s.client.Do(req, &result)
-		if err != nil {
-			return nil, err
-		}
-
-		return result.Data, nil
+		return fmt.Errorf("unsupported VM type: %s", vm.Type)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("unsupported VM type: %s", vm.Type)
+	return interfaces, nil
 }
 
 // GetFilesystemInfo retrieves VM filesystem information (QEMU with guest agent)
 func (s *VMsService) GetFilesystemInfo(vmid int) ([]FilesystemInfo, error) {
-	vm, err := s.GetVMResource(vmid)
-	if err != nil {
-		return nil, err
-	}
-
-	if vm.Type != "qemu" {
-		return nil, fmt.Errorf("filesystem info is only supported for QEMU VMs with guest agent")
-	}
-
-	req, err := s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/get-fsinfo", vm.Node, vmid), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var result struct {
 		Data struct {
 			Result []FilesystemInfo `json:"result"`
 		} `json:"data"`
 	}
-	_, err = s.client.Do(req, &result)
+	err := s.do(vmid, func(vm *VM) (*req.Request, error) {
+		if vm.Type != "qemu" {
+			return nil, fmt.Errorf("filesystem info is only supported for QEMU VMs with guest agent")
+		}
+
+		return s.client.NewRequest("GET", fmt.Sprintf("nodes/%s/qemu/%d/agent/get-fsinfo", vm.Node, vmid), nil)
+	}, &result)
 	if err != nil {
 		return nil, err
 	}
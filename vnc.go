@@ -0,0 +1,156 @@
+package pve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// VNCServer relays the VNC console of a guest to a local listener so that any
+// VNC viewer can connect. A vncproxy ticket is valid for a single connection,
+// so the server accepts one viewer and ends when it disconnects.
+type VNCServer struct {
+	Password string // Password to enter in the viewer
+
+	ws   *websocket.Conn
+	ln   net.Listener
+	done chan struct{}
+	once sync.Once
+	err  error
+}
+
+// ServeVNC relays the VNC console of a QEMU VM to ln. If ln is nil, a listener
+// on a random localhost port is created. The session ends when the viewer
+// disconnects, Close is called or ctx is done.
+func (s *QEMUService) ServeVNC(ctx context.Context, node string, vmid int, ln net.Listener) (*VNCServer, error) {
+	return s.client.serveVNC(ctx, fmt.Sprintf("nodes/%s/qemu/%d", node, vmid), ln)
+}
+
+// ServeVNC relays the VNC console of an LXC container to ln. If ln is nil, a
+// listener on a random localhost port is created. The session ends when the
+// viewer disconnects, Close is called or ctx is done.
+func (s *LXCService) ServeVNC(ctx context.Context, node string, vmid int, ln net.Listener) (*VNCServer, error) {
+	return s.client.serveVNC(ctx, fmt.Sprintf("nodes/%s/lxc/%d", node, vmid), ln)
+}
+
+// vncProxy requests a vncproxy ticket with a generated password for the guest at path
+func (c *Client) vncProxy(ctx context.Context, path string) (*TermProxy, error) {
+	req, err := c.NewRequest("POST", path+"/vncproxy", map[string]any{
+		"websocket":         1,
+		"generate-password": 1,
+	}, WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data *TermProxy
+	}
+	_, err = c.Do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Data == nil {
+		return nil, errors.New("no ticket returned by vncproxy")
+	}
+
+	return result.Data, nil
+}
+
+// serveVNC requests a vncproxy ticket for the guest at path, connects to its
+// vncwebsocket and relays it to ln
+func (c *Client) serveVNC(ctx context.Context, path string, ln net.Listener) (*VNCServer, error) {
+	proxy, err := c.vncProxy(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	// The proxy only waits a few seconds for the websocket, connect right away
+	ws, err := c.dialVNCWebsocket(ctx, path, proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	if ln == nil {
+		if ln, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+			ws.Close()
+			return nil, err
+		}
+	}
+
+	v := &VNCServer{
+		Password: proxy.Password,
+		ws:       ws,
+		ln:       ln,
+		done:     make(chan struct{}),
+	}
+	if v.Password == "" {
+		// Without a generated password the ticket authenticates the viewer
+		v.Password = proxy.Ticket
+	}
+
+	stop := context.AfterFunc(ctx, func() { v.finish(ctx.Err()) })
+	go func() {
+		defer stop()
+		v.serve()
+	}()
+
+	return v, nil
+}
+
+// serve accepts a single viewer and relays the RFB stream until either side closes
+func (v *VNCServer) serve() {
+	conn, err := v.ln.Accept()
+	v.ln.Close()
+	if err != nil {
+		v.finish(err)
+		return
+	}
+
+	errc := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(v.ws, conn)
+		errc <- err
+	}()
+	go func() {
+		_, err := io.Copy(conn, v.ws)
+		errc <- err
+	}()
+
+	err = <-errc
+	conn.Close()
+	v.finish(err)
+}
+
+// Addr returns the local address the viewer connects to
+func (v *VNCServer) Addr() net.Addr {
+	return v.ln.Addr()
+}
+
+// Wait blocks until the session ended and returns the error that ended it
+func (v *VNCServer) Wait() error {
+	<-v.done
+	return v.err
+}
+
+// Close ends the session
+func (v *VNCServer) Close() error {
+	v.finish(nil)
+	return nil
+}
+
+// finish closes all connections and records the first error
+func (v *VNCServer) finish(err error) {
+	v.once.Do(func() {
+		v.err = err
+		v.ln.Close()
+		v.ws.Close()
+		close(v.done)
+	})
+}